@@ -0,0 +1,89 @@
+// Package ask answers questions about the journal from its own notes: a
+// Retriever picks the relevant excerpts and the configured model answers
+// from them, citing where each fact came from.
+package ask
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabrielfornes/teatime/internal/config"
+	"github.com/gabrielfornes/teatime/internal/llm"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// DefaultPassages is how many excerpts are sent along with a question.
+const DefaultPassages = 8
+
+// maxPassageChars caps each excerpt so that several fit in the context of
+// a small local model.
+const maxPassageChars = 1500
+
+// Passage is an excerpt of a note.
+type Passage struct {
+	Project  string
+	Category storage.Category
+	Name     string
+	Text     string
+}
+
+// Source returns the note the passage comes from, as "project/category/name".
+func (p Passage) Source() string {
+	return p.Project + "/" + string(p.Category) + "/" + p.Name
+}
+
+// Retriever finds the passages most likely to answer a question.
+type Retriever interface {
+	// Retrieve returns up to limit passages, most relevant first.
+	Retrieve(ctx context.Context, question string, limit int) ([]Passage, error)
+}
+
+// NewRetriever returns the retriever cfg calls for: embedding similarity
+// if an embedding model is configured, and the search index otherwise.
+// Text sent to the embedding model first goes through filter.
+func NewRetriever(store *storage.Store, cfg config.LLMConfig, filter llm.Filter) Retriever {
+	if embedder, err := llm.NewEmbedder(cfg); err == nil {
+		return &EmbeddingRetriever{
+			Store:     store,
+			Embedder:  llm.EmbedderWithFilter(embedder, filter),
+			Model:     cfg.EmbeddingModel,
+			CachePath: filepath.Join(store.Root, ".index", "embeddings.json"),
+		}
+	}
+	return &IndexRetriever{Store: store}
+}
+
+const systemPrompt = `You answer questions about the user's work journal using only the excerpts below.
+Each excerpt starts with its source in square brackets, like [project/days/2025-01-15].
+After every statement, cite the sources it rests on in that same bracketed form.
+If the excerpts don't answer the question, say so instead of guessing. Be brief.`
+
+// Messages builds the chat that asks the model to answer question from
+// passages.
+func Messages(question string, passages []Passage) []llm.Message {
+	var b strings.Builder
+	for _, p := range passages {
+		b.WriteString("[" + p.Source() + "]\n")
+		b.WriteString(strings.TrimSpace(p.Text))
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Question: " + question)
+	return []llm.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: b.String()},
+	}
+}
+
+// Sources returns the distinct notes the passages come from, in order.
+func Sources(passages []Passage) []Passage {
+	seen := make(map[string]bool)
+	var sources []Passage
+	for _, p := range passages {
+		if !seen[p.Source()] {
+			seen[p.Source()] = true
+			sources = append(sources, p)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,203 @@
+package ask
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gabrielfornes/teatime/internal/llm"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// embedBatch is how many chunks are sent to the embedder per request.
+const embedBatch = 32
+
+// EmbeddingRetriever splits every note into paragraph-sized chunks and
+// returns those whose embeddings are closest to the question's. Vectors
+// are cached by chunk content, so only new or edited text is embedded on
+// later questions.
+type EmbeddingRetriever struct {
+	Store     *storage.Store
+	Embedder  llm.Embedder
+	Model     string // recorded in the cache, which is discarded when it changes
+	CachePath string
+}
+
+// embeddingCache is the JSON layout of the cache file.
+type embeddingCache struct {
+	Model   string               `json:"model"`
+	Vectors map[string][]float32 `json:"vectors"` // sha256 of the chunk → vector
+}
+
+// Retrieve implements Retriever.
+func (r *EmbeddingRetriever) Retrieve(ctx context.Context, question string, limit int) ([]Passage, error) {
+	chunks, err := r.chunks()
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	cache := r.loadCache()
+	fresh := make(map[string][]float32, len(chunks))
+	var missing []int
+	for i, c := range chunks {
+		key := chunkKey(c)
+		if v, ok := cache.Vectors[key]; ok {
+			fresh[key] = v
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	for start := 0; start < len(missing); start += embedBatch {
+		batch := missing[start:min(start+embedBatch, len(missing))]
+		texts := make([]string, len(batch))
+		for j, i := range batch {
+			texts[j] = chunkText(chunks[i])
+		}
+		vectors, err := r.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("could not embed notes: %w", err)
+		}
+		for j, i := range batch {
+			fresh[chunkKey(chunks[i])] = vectors[j]
+		}
+	}
+	if len(missing) > 0 || len(fresh) != len(cache.Vectors) {
+		// Best-effort: a cache that can't be written is rebuilt next time.
+		_ = r.saveCache(embeddingCache{Model: r.Model, Vectors: fresh})
+	}
+
+	q, err := r.Embedder.Embed(ctx, []string{question})
+	if err != nil {
+		return nil, fmt.Errorf("could not embed question: %w", err)
+	}
+	scores := make([]float64, len(chunks))
+	for i, c := range chunks {
+		scores[i] = cosine(q[0], fresh[chunkKey(c)])
+	}
+	order := make([]int, len(chunks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+	passages := make([]Passage, len(order))
+	for i, idx := range order {
+		passages[i] = chunks[idx]
+	}
+	return passages, nil
+}
+
+// chunks splits every note of every project into passages of whole
+// paragraphs, each at most maxPassageChars long unless a single paragraph
+// is longer.
+func (r *EmbeddingRetriever) chunks() ([]Passage, error) {
+	projects, err := r.Store.ListAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	var chunks []Passage
+	for _, p := range projects {
+		for _, cat := range storage.AllCategories {
+			notes, err := r.Store.ListNotes(p, cat)
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range notes {
+				content, err := r.Store.ReadNote(p, cat, n.Name)
+				if err != nil {
+					return nil, err
+				}
+				var b strings.Builder
+				flush := func() {
+					if text := strings.TrimSpace(b.String()); text != "" {
+						chunks = append(chunks, Passage{Project: p, Category: cat, Name: n.Name, Text: text})
+					}
+					b.Reset()
+				}
+				for _, para := range strings.Split(content, "\n\n") {
+					if b.Len() > 0 && b.Len()+len(para) > maxPassageChars {
+						flush()
+					}
+					b.WriteString(para + "\n\n")
+				}
+				flush()
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// chunkText is what gets embedded for a chunk: its source, which carries
+// the date, and its text.
+func chunkText(p Passage) string {
+	return p.Source() + "\n" + p.Text
+}
+
+func chunkKey(p Passage) string {
+	sum := sha256.Sum256([]byte(chunkText(p)))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCache reads the cache file. A missing or unreadable cache, or one
+// made with another model, is treated as empty.
+func (r *EmbeddingRetriever) loadCache() embeddingCache {
+	empty := embeddingCache{Model: r.Model, Vectors: map[string][]float32{}}
+	data, err := os.ReadFile(r.CachePath)
+	if err != nil {
+		return empty
+	}
+	var cache embeddingCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Model != r.Model || cache.Vectors == nil {
+		return empty
+	}
+	return cache
+}
+
+func (r *EmbeddingRetriever) saveCache(cache embeddingCache) error {
+	if r.CachePath == "" {
+		return errors.New("no cache path")
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.CachePath), 0755); err != nil {
+		return err
+	}
+	tmp := r.CachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.CachePath)
+}
+
+// cosine returns the cosine similarity of two vectors, or 0 if their
+// lengths differ or either is zero.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,97 @@
+package ask
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// IndexRetriever ranks notes with the search index (see
+// storage.Store.RankNotes) and excerpts the lines around the question's
+// words. It needs no model of its own.
+type IndexRetriever struct {
+	Store *storage.Store
+}
+
+// Retrieve implements Retriever.
+func (r *IndexRetriever) Retrieve(ctx context.Context, question string, limit int) ([]Passage, error) {
+	notes, err := r.Store.RankNotes(question, limit)
+	if err != nil {
+		return nil, err
+	}
+	words := storage.QuestionWords(question)
+	var passages []Passage
+	for _, n := range notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content, err := r.Store.ReadNote(n.Project, n.Category, n.Name)
+		if err != nil {
+			return nil, err
+		}
+		passages = append(passages, Passage{
+			Project:  n.Project,
+			Category: n.Category,
+			Name:     n.Name,
+			Text:     excerpt(content, words),
+		})
+	}
+	return passages, nil
+}
+
+// excerpt returns the lines of content that mention any of words, with a
+// line of context on either side and "…" marking skipped lines, cut to
+// maxPassageChars. Short notes are returned whole.
+func excerpt(content string, words []string) string {
+	if len(content) <= maxPassageChars {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		lower := strings.ToLower(line)
+		for _, w := range words {
+			if strings.Contains(lower, w) {
+				for j := max(i-1, 0); j <= min(i+1, len(lines)-1); j++ {
+					keep[j] = true
+				}
+				break
+			}
+		}
+	}
+
+	var b strings.Builder
+	skipped := false
+	for i, line := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped && b.Len() > 0 {
+			b.WriteString("…\n")
+		}
+		skipped = false
+		if b.Len()+len(line) > maxPassageChars {
+			b.WriteString("…\n")
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+	if b.Len() == 0 {
+		return truncate(content, maxPassageChars)
+	}
+	return b.String()
+}
+
+// truncate cuts s to at most n bytes at a line break where possible.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := s[:n]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.ToValidUTF8(cut, "") + "\n…"
+}
@@ -0,0 +1,711 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabrielfornes/teatime/internal/ask"
+	"github.com/gabrielfornes/teatime/internal/config"
+	"github.com/gabrielfornes/teatime/internal/llm"
+	"github.com/gabrielfornes/teatime/internal/redact"
+	"github.com/gabrielfornes/teatime/internal/report"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// ErrUsage is returned when a command is invoked with bad arguments.
+// The usage text has already been printed when it is returned.
+var ErrUsage = errors.New("invalid usage")
+
+// App runs teatime's headless subcommands against a Store.
+type App struct {
+	Store  *storage.Store
+	Config *config.Config
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// command is a single subcommand.
+type command struct {
+	name    string
+	args    string // argument synopsis for usage output
+	summary string
+	run     func(a *App, args []string) error
+}
+
+// commands lists every subcommand in the order they appear in help output.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"projects", "[-all]", "List projects (-all includes archived ones)", (*App).runProjects},
+		{"project", "<project> [-name n] [-description d] [-color c] [-client c] [-rate r] [-currency c] [-set key=value]", "Show or edit a project's metadata", (*App).runProject},
+		{"list", "<project> <category>", "List notes in a category", (*App).runList},
+		{"show", "<project> [category] [name]", "Print a note (default: today's daily note)", (*App).runShow},
+		{"add", "<project> <text> [-category c] [-name n]", "Append a paragraph to a note (default: today's daily note)", (*App).runAdd},
+		{"log", "<project> [text]", "Append a timestamped bullet to today's note (text or stdin)", (*App).runLog},
+		{"start", "<project>", "Start timing a project (stops any other running timer)", (*App).runStart},
+		{"stop", "[note]", "Stop the running timer and log the session", (*App).runStop},
+		{"report", "[week|month|2025-W03|2025-01] [-format table|csv|markdown] [-project p]", "Print a timesheet of hours per project per day", (*App).runReport},
+		{"invoice", "<project> [-month 2025-01] [-format markdown|html] [-number n]", "Print an invoice for a month of a project's hours", (*App).runInvoice},
+		{"search", "<query...> [-project p] [-limit n]", "Search all notes, printing project/category/name:line hits", (*App).runSearch},
+		{"ask", "<question...> [-limit n] [-preview]", "Answer a question from your notes with the configured LLM, citing sources", (*App).runAsk},
+		{"export", "<project> <category> [name]", "Print the redacted entries a summary is written from", (*App).runExport},
+		{"tags", "[tag]", "List tags with counts, or the notes carrying a tag, oldest first", (*App).runTags},
+		{"trash", "", "List deleted projects and notes", (*App).runTrash},
+		{"restore", "<trash-id>", "Restore a deleted project or note", (*App).runRestore},
+		{"reindex", "", "Rebuild the search index from scratch", (*App).runReindex},
+		{"help", "", "Show this help", (*App).runHelp},
+	}
+}
+
+// New returns an App wired to the process's standard streams.
+func New(store *storage.Store, cfg *config.Config) *App {
+	return &App{
+		Store:  store,
+		Config: cfg,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+// Run dispatches args[0] to the matching subcommand.
+func (a *App) Run(args []string) error {
+	if len(args) == 0 {
+		return a.runHelp(nil)
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(a, args[1:])
+		}
+	}
+	fmt.Fprintf(a.Stderr, "teatime: unknown command %q\n\n", args[0])
+	a.printUsage(a.Stderr)
+	return ErrUsage
+}
+
+// --- Commands ---
+
+func (a *App) runProjects(args []string) error {
+	fs := a.flagSet("projects")
+	all := fs.Bool("all", false, "include archived projects")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return ErrUsage
+	}
+	if len(pos) != 0 {
+		return a.usage("projects")
+	}
+
+	list := a.Store.ListProjects
+	if *all {
+		list = a.Store.ListAllProjects
+	}
+	projects, err := list()
+	if err != nil {
+		return err
+	}
+	for _, p := range projects {
+		meta, err := a.Store.ProjectMeta(p)
+		if err != nil {
+			return err
+		}
+		line := p
+		if title := meta.Title(p); title != p {
+			line += "  " + title
+		}
+		if meta.Archived() {
+			line += " (archived)"
+		}
+		fmt.Fprintln(a.Stdout, line)
+	}
+	return nil
+}
+
+func (a *App) runProject(args []string) error {
+	fs := a.flagSet("project")
+	name := fs.String("name", "", "display name")
+	description := fs.String("description", "", "one-line description")
+	color := fs.String("color", "", "hex or ANSI color, e.g. #f25d94 or 205")
+	client := fs.String("client", "", "client billed on invoices")
+	rate := fs.Float64("rate", 0, "hourly rate")
+	currency := fs.String("currency", "", "currency code, e.g. EUR")
+	settings := map[string]string{}
+	fs.Func("set", "set a per-project setting, key=value (empty value removes it)", func(v string) error {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value, got %q", v)
+		}
+		settings[key] = value
+		return nil
+	})
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return ErrUsage
+	}
+	if len(pos) != 1 {
+		return a.usage("project")
+	}
+	project, err := a.project(pos[0])
+	if err != nil {
+		return err
+	}
+	meta, err := a.Store.ProjectMeta(project)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	fs.Visit(func(f *flag.Flag) {
+		changed = true
+		switch f.Name {
+		case "name":
+			meta.DisplayName = strings.TrimSpace(*name)
+		case "description":
+			meta.Description = *description
+		case "color":
+			meta.Color = *color
+		case "client":
+			meta.Client = *client
+		case "rate":
+			meta.Rate = *rate
+		case "currency":
+			meta.Currency = strings.ToUpper(*currency)
+		}
+	})
+	for key, value := range settings {
+		if meta.Settings == nil {
+			meta.Settings = map[string]string{}
+		}
+		if value == "" {
+			delete(meta.Settings, key)
+		} else {
+			meta.Settings[key] = value
+		}
+	}
+	if changed {
+		return a.Store.SaveProjectMeta(project, meta)
+	}
+
+	fmt.Fprintf(a.Stdout, "name:        %s\n", meta.Title(project))
+	fmt.Fprintf(a.Stdout, "description: %s\n", meta.Description)
+	fmt.Fprintf(a.Stdout, "color:       %s\n", meta.Color)
+	fmt.Fprintf(a.Stdout, "created:     %s\n", meta.Created)
+	fmt.Fprintf(a.Stdout, "status:      %s\n", meta.Status)
+	fmt.Fprintf(a.Stdout, "client:      %s\n", meta.Client)
+	fmt.Fprintf(a.Stdout, "rate:        %s\n", strconv.FormatFloat(meta.Rate, 'f', -1, 64))
+	fmt.Fprintf(a.Stdout, "currency:    %s\n", meta.Currency)
+	keys := make([]string, 0, len(meta.Settings))
+	for k := range meta.Settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(a.Stdout, "settings.%s: %s\n", k, meta.Settings[k])
+	}
+	return nil
+}
+
+func (a *App) runList(args []string) error {
+	if len(args) != 2 {
+		return a.usage("list")
+	}
+	project, err := a.project(args[0])
+	if err != nil {
+		return err
+	}
+	category, err := storage.ParseCategory(args[1])
+	if err != nil {
+		return err
+	}
+	notes, err := a.Store.ListNotes(project, category)
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		fmt.Fprintln(a.Stdout, n.Name)
+	}
+	return nil
+}
+
+func (a *App) runShow(args []string) error {
+	if len(args) < 1 || len(args) > 3 {
+		return a.usage("show")
+	}
+	project, err := a.project(args[0])
+	if err != nil {
+		return err
+	}
+	category := storage.CategoryDaily
+	if len(args) > 1 {
+		if category, err = storage.ParseCategory(args[1]); err != nil {
+			return err
+		}
+	}
+	name := storage.DefaultNameForCategory(category)
+	if len(args) > 2 {
+		name = args[2]
+	}
+
+	if !a.Store.NoteExists(project, category, name) {
+		return fmt.Errorf("no note %s/%s/%s", project, category, name)
+	}
+	content, err := a.Store.ReadNote(project, category, name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(a.Stdout, content)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		fmt.Fprintln(a.Stdout)
+	}
+	return nil
+}
+
+func (a *App) runAdd(args []string) error {
+	fs := a.flagSet("add")
+	categoryFlag := fs.String("category", string(storage.CategoryDaily), "note category")
+	nameFlag := fs.String("name", "", "note name (default: current period)")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return ErrUsage
+	}
+	if len(pos) != 2 {
+		return a.usage("add")
+	}
+
+	project, err := a.project(pos[0])
+	if err != nil {
+		return err
+	}
+	category, err := storage.ParseCategory(*categoryFlag)
+	if err != nil {
+		return err
+	}
+	name := *nameFlag
+	if name == "" {
+		name = storage.DefaultNameForCategory(category)
+	}
+	text := strings.TrimSpace(pos[1])
+	if text == "" {
+		return fmt.Errorf("nothing to add")
+	}
+	return a.Store.AppendParagraph(project, category, name, text+"\n")
+}
+
+func (a *App) runLog(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return a.usage("log")
+	}
+	project, err := a.project(args[0])
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	if len(args) == 2 && args[1] != "-" {
+		lines = []string{args[1]}
+	} else {
+		scanner := bufio.NewScanner(a.Stdin)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("could not read stdin: %w", err)
+		}
+	}
+
+	stamp := time.Now().Format("15:04")
+	var entry strings.Builder
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fmt.Fprintf(&entry, "- %s %s\n", stamp, line)
+	}
+	if entry.Len() == 0 {
+		return fmt.Errorf("nothing to log")
+	}
+	return a.Store.AppendToNote(project, storage.CategoryDaily, storage.TodayName(), entry.String())
+}
+
+func (a *App) runStart(args []string) error {
+	if len(args) != 1 {
+		return a.usage("start")
+	}
+	project, err := a.project(args[0])
+	if err != nil {
+		return err
+	}
+	stopped, err := a.Store.StartTimer(project)
+	if err != nil {
+		return err
+	}
+	if stopped != nil {
+		fmt.Fprintf(a.Stdout, "Stopped %s after %s\n", stopped.Project, storage.FormatDuration(stopped.Duration()))
+	}
+	fmt.Fprintf(a.Stdout, "Started %s at %s\n", project, time.Now().Format("15:04"))
+	return nil
+}
+
+func (a *App) runStop(args []string) error {
+	session, err := a.Store.StopTimer(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Stdout, "Stopped %s after %s\n", session.Project, storage.FormatDuration(session.Duration()))
+	return nil
+}
+
+func (a *App) runReport(args []string) error {
+	fs := a.flagSet("report")
+	formatName := fs.String("format", "table", "output format: table, csv or markdown")
+	only := fs.String("project", "", "only include this project")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return ErrUsage
+	}
+	if len(pos) > 1 {
+		return a.usage("report")
+	}
+	format, err := report.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	period := "week"
+	if len(pos) == 1 {
+		period = pos[0]
+	}
+	var category storage.Category
+	switch period {
+	case "week":
+		category, period = storage.CategoryWeekly, storage.CurrentWeekName()
+	case "month":
+		category, period = storage.CategoryMonthly, storage.CurrentMonthName()
+	default:
+		if category, err = storage.ParsePeriod(period); err != nil {
+			return err
+		}
+	}
+
+	var projects []string
+	if *only != "" {
+		project, err := a.project(*only)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, project)
+	}
+	ts, err := a.Store.Timesheet(category, period, projects...)
+	if err != nil {
+		return err
+	}
+	return report.Write(a.Stdout, ts, format)
+}
+
+func (a *App) runInvoice(args []string) error {
+	fs := a.flagSet("invoice")
+	month := fs.String("month", storage.CurrentMonthName(), "month to bill, e.g. 2025-01")
+	formatName := fs.String("format", "markdown", "output format: markdown or html")
+	number := fs.String("number", "", "invoice number (default <project>-<month>)")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return ErrUsage
+	}
+	if len(pos) != 1 {
+		return a.usage("invoice")
+	}
+	project, err := a.project(pos[0])
+	if err != nil {
+		return err
+	}
+	format, err := report.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	if format != report.FormatMarkdown && format != report.FormatHTML {
+		return fmt.Errorf("invoices can't be written as %s (want markdown or html)", format)
+	}
+
+	inv, err := a.Store.Invoice(project, *month)
+	if err != nil {
+		return err
+	}
+	if *number != "" {
+		inv.Number = *number
+	}
+	if inv.Rate == 0 {
+		fmt.Fprintf(a.Stderr, "teatime: %s has no hourly rate; set one with: teatime project %s -rate 90\n", project, project)
+	}
+	return report.WriteInvoice(a.Stdout, inv, format)
+}
+
+func (a *App) runSearch(args []string) error {
+	fs := a.flagSet("search")
+	projectFlag := fs.String("project", "", "only search this project")
+	limitFlag := fs.Int("limit", storage.DefaultSearchLimit, "maximum number of hits")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return ErrUsage
+	}
+	if len(pos) == 0 {
+		return a.usage("search")
+	}
+	if *projectFlag != "" {
+		if _, err := a.project(*projectFlag); err != nil {
+			return err
+		}
+	}
+
+	hits, err := a.Store.SearchProject(*projectFlag, strings.Join(pos, " "), *limitFlag)
+	if err != nil {
+		return err
+	}
+	for _, h := range hits {
+		fmt.Fprintf(a.Stdout, "%s: %s\n", h.Location(), strings.TrimSpace(h.Text))
+	}
+	return nil
+}
+
+func (a *App) runAsk(args []string) error {
+	fs := a.flagSet("ask")
+	limit := fs.Int("limit", ask.DefaultPassages, "number of excerpts to send with the question")
+	preview := fs.Bool("preview", false, "print exactly what would be sent, without sending it")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return ErrUsage
+	}
+	if len(pos) == 0 {
+		return a.usage("ask")
+	}
+	question := strings.Join(pos, " ")
+	redactor, err := redact.New(a.Config.Redact)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	passages, err := ask.NewRetriever(a.Store, a.Config.LLM, redactor.String).Retrieve(ctx, question, *limit)
+	if err != nil {
+		return err
+	}
+	if len(passages) == 0 {
+		fmt.Fprintln(a.Stdout, "No notes look relevant to that question.")
+		return nil
+	}
+	messages := ask.Messages(question, passages)
+	if *preview {
+		return a.printPreview(redactor, messages)
+	}
+
+	provider, err := llm.New(a.Config.LLM)
+	if err != nil {
+		return err
+	}
+	stream, err := llm.WithFilter(provider, redactor.String).Stream(ctx, messages)
+	if err != nil {
+		return err
+	}
+	for d := range stream {
+		if d.Err != nil {
+			return d.Err
+		}
+		fmt.Fprint(a.Stdout, d.Text)
+	}
+
+	fmt.Fprintln(a.Stdout)
+	fmt.Fprintln(a.Stdout)
+	fmt.Fprintln(a.Stdout, "Sources:")
+	for _, p := range ask.Sources(passages) {
+		fmt.Fprintf(a.Stdout, "  %s\n", p.Source())
+	}
+	return nil
+}
+
+// printPreview prints messages as they would be sent to the model, then
+// what was redacted from them.
+func (a *App) printPreview(redactor *redact.Redactor, messages []llm.Message) error {
+	var all []redact.Redaction
+	for _, m := range messages {
+		content, redactions := redactor.Redact(m.Content)
+		all = append(all, redactions...)
+		fmt.Fprintf(a.Stdout, "── %s ──\n%s\n\n", m.Role, strings.TrimRight(content, "\n"))
+	}
+	fmt.Fprintln(a.Stderr, redact.Describe(all))
+	return nil
+}
+
+func (a *App) runExport(args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return a.usage("export")
+	}
+	project, err := a.project(args[0])
+	if err != nil {
+		return err
+	}
+	category, err := storage.ParseCategory(args[1])
+	if err != nil {
+		return err
+	}
+	if category == storage.CategoryDaily {
+		return fmt.Errorf("daily notes aren't summaries; export weeks, months, quarters or years")
+	}
+	name := storage.DefaultNameForCategory(category)
+	if len(args) > 2 {
+		name = args[2]
+	}
+	redactor, err := redact.New(a.Config.Redact)
+	if err != nil {
+		return err
+	}
+
+	content, err := a.Store.GatherReferenceContent(project, category, name)
+	if err != nil {
+		return err
+	}
+	content, redactions := redactor.Redact(content)
+	fmt.Fprintln(a.Stdout, strings.TrimRight(content, "\n"))
+	if len(redactions) > 0 {
+		fmt.Fprintf(a.Stderr, "teatime: %s\n", redact.Describe(redactions))
+	}
+	return nil
+}
+
+func (a *App) runTags(args []string) error {
+	switch len(args) {
+	case 0:
+		tags, err := a.Store.ListTags()
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			fmt.Fprintf(a.Stdout, "%4d  #%s\n", t.Count, t.Tag)
+		}
+		return nil
+	case 1:
+		notes, err := a.Store.NotesWithTag(args[0])
+		if err != nil {
+			return err
+		}
+		for _, n := range notes {
+			fmt.Fprintln(a.Stdout, n.Label())
+		}
+		return nil
+	default:
+		return a.usage("tags")
+	}
+}
+
+func (a *App) runTrash(args []string) error {
+	if len(args) != 0 {
+		return a.usage("trash")
+	}
+	items, err := a.Store.ListTrash()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		fmt.Fprintf(a.Stdout, "%s  %-7s  %s  (deleted %s)\n",
+			item.ID, item.Kind, item.Label(), item.DeletedAt.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func (a *App) runRestore(args []string) error {
+	if len(args) != 1 {
+		return a.usage("restore")
+	}
+	return a.Store.RestoreTrash(args[0])
+}
+
+func (a *App) runReindex(args []string) error {
+	if len(args) != 0 {
+		return a.usage("reindex")
+	}
+	return a.Store.RebuildIndex()
+}
+
+func (a *App) runHelp(args []string) error {
+	a.printUsage(a.Stdout)
+	return nil
+}
+
+// --- Helpers ---
+
+// project checks that a project exists and returns its name.
+func (a *App) project(name string) (string, error) {
+	if !a.Store.ProjectExists(name) {
+		return "", fmt.Errorf("project %q does not exist", name)
+	}
+	return name, nil
+}
+
+func (a *App) printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: teatime [-root dir] [-config file] [command] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "With no command, teatime opens the interactive TUI.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		synopsis := c.name
+		if c.args != "" {
+			synopsis += " " + c.args
+		}
+		fmt.Fprintf(w, "  %-48s %s\n", synopsis, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Categories: days, weeks, months, quarters, years (or daily, weekly, ...).")
+}
+
+// usage prints the synopsis for a single command and returns ErrUsage.
+func (a *App) usage(name string) error {
+	for _, c := range commands {
+		if c.name == name {
+			fmt.Fprintf(a.Stderr, "Usage: teatime %s %s\n", c.name, c.args)
+			break
+		}
+	}
+	return ErrUsage
+}
+
+// flagSet returns a FlagSet for a subcommand that reports errors to Stderr.
+func (a *App) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("teatime "+name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	return fs
+}
+
+// parseInterspersed parses flags that may appear anywhere among the
+// positional arguments and returns the positional arguments in order.
+// The standard flag package stops at the first non-flag argument, which
+// would make `teatime add alpha "text" -category weeks` ignore the flag.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		before := args
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if consumed := len(before) - len(args); consumed > 0 && before[consumed-1] == "--" {
+			return append(positional, args...), nil
+		}
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
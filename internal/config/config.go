@@ -0,0 +1,256 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvHome is the environment variable that overrides the storage root.
+const EnvHome = "TEATIME_HOME"
+
+// Config holds the user settings for teatime.
+//
+// Settings are read from the global config file (see DefaultPath) and then
+// overlaid with the optional .config.yaml inside the storage root, so a
+// journal can carry its own settings with it. A journal may come from
+// someone else, so its .config.yaml cannot point the LLM at another
+// endpoint or key, nor turn off redaction; it can only add redact rules.
+type Config struct {
+	Root        string        `yaml:"root"`         // storage root, e.g. ~/.teatime
+	LockTimeout time.Duration `yaml:"lock_timeout"` // how long writes wait for other processes, e.g. 10s
+	Git         GitConfig     `yaml:"git"`
+	Trash       TrashConfig   `yaml:"trash"`
+	LLM         LLMConfig     `yaml:"llm"`
+	Redact      RedactConfig  `yaml:"redact"`
+
+	path string // config file this was loaded from, if any
+}
+
+// GitConfig controls committing the journal to git on every save.
+type GitConfig struct {
+	AutoCommit  bool          `yaml:"autocommit"`
+	BatchWindow time.Duration `yaml:"batch_window"` // saves closer together than this share a commit
+}
+
+// TrashConfig controls how long deleted projects and notes are kept.
+type TrashConfig struct {
+	PurgeAfterDays int `yaml:"purge_after_days"` // 0 keeps trash forever
+}
+
+// LLMConfig points summary drafting and questions at an OpenAI-compatible
+// endpoint, such as a local Ollama.
+type LLMConfig struct {
+	Endpoint  string `yaml:"endpoint"`    // base URL, e.g. http://localhost:11434/v1
+	Model     string `yaml:"model"`       // e.g. llama3.1
+	APIKeyEnv string `yaml:"api_key_env"` // environment variable holding the API key, if any
+	Prompt    string `yaml:"prompt"`      // instructions for drafting summaries; empty uses the built-in prompt
+
+	// EmbeddingModel, if set, makes `ask` find relevant notes by embedding
+	// similarity (e.g. nomic-embed-text) instead of the search index.
+	EmbeddingModel string `yaml:"embedding_model"`
+}
+
+// APIKey returns the API key from the environment, or "" if none is set.
+func (c LLMConfig) APIKey() string {
+	if c.APIKeyEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.APIKeyEnv)
+}
+
+// RedactConfig controls what is scrubbed from notes before they are sent
+// to an LLM or exported.
+type RedactConfig struct {
+	Secrets bool         `yaml:"secrets"` // built-in secret detector; on unless set to false
+	Rules   []RedactRule `yaml:"rules"`
+}
+
+// RedactRule replaces every match of a regular expression.
+type RedactRule struct {
+	Name    string `yaml:"name"`    // e.g. "customer"; shown in previews
+	Pattern string `yaml:"pattern"` // Go regexp, e.g. (?i)\bacme corp\b
+	Replace string `yaml:"replace"` // may refer to groups as $1; empty means [REDACTED:name]
+}
+
+// Options are the command-line overrides passed in from main.
+type Options struct {
+	Root       string // --root flag
+	ConfigPath string // --config flag
+}
+
+// DefaultPath returns the location of the global config file,
+// $XDG_CONFIG_HOME/teatime/config.yaml (~/.config/teatime/config.yaml).
+func DefaultPath() (string, error) {
+	dir, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "teatime", "config.yaml"), nil
+}
+
+// Load reads the config file and resolves the storage root.
+//
+// The root is chosen in this order: the --root flag, $TEATIME_HOME, the
+// "root" key of the config file, ~/.teatime if it already exists, and
+// finally $XDG_DATA_HOME/teatime (~/.local/share/teatime).
+func Load(opts Options) (*Config, error) {
+	path := opts.ConfigPath
+	if path == "" {
+		p, err := DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+
+	cfg := &Config{Redact: RedactConfig{Secrets: true}}
+	if err := cfg.readFile(path, opts.ConfigPath != ""); err != nil {
+		return nil, err
+	}
+
+	root, err := cfg.resolveRoot(opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	// The root's own .config.yaml may tune settings, but it cannot move the root.
+	if err := cfg.readRootFile(filepath.Join(root, ".config.yaml")); err != nil {
+		return nil, err
+	}
+	cfg.Root = root
+	return cfg, nil
+}
+
+// Path returns the global config file that was read, or "" if none existed.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// readFile decodes a YAML config file on top of c. A missing file is
+// only an error when required is set.
+func (c *Config) readFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("could not read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	if c.path == "" {
+		c.path = path
+	}
+	return nil
+}
+
+// rootOverrides holds the settings a root .config.yaml must not change.
+// Pointers tell a key that is set apart from one that is absent.
+type rootOverrides struct {
+	LLM struct {
+		Endpoint  *string `yaml:"endpoint"`
+		APIKeyEnv *string `yaml:"api_key_env"`
+	} `yaml:"llm"`
+	Redact struct {
+		Secrets *bool `yaml:"secrets"`
+	} `yaml:"redact"`
+}
+
+// readRootFile decodes the root's .config.yaml on top of c. It refuses
+// settings that would send notes somewhere else or unredacted, and adds
+// its redact rules to the global ones instead of replacing them.
+func (c *Config) readRootFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("could not read config: %w", err)
+	}
+	var guard rootOverrides
+	if err := yaml.Unmarshal(data, &guard); err != nil {
+		return fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	var refused []string
+	if guard.LLM.Endpoint != nil {
+		refused = append(refused, "llm.endpoint")
+	}
+	if guard.LLM.APIKeyEnv != nil {
+		refused = append(refused, "llm.api_key_env")
+	}
+	if guard.Redact.Secrets != nil && !*guard.Redact.Secrets {
+		refused = append(refused, "redact.secrets: false")
+	}
+	if len(refused) > 0 {
+		return fmt.Errorf("could not load config %s: %s can only be set in the global config", path, strings.Join(refused, ", "))
+	}
+
+	rules := c.Redact.Rules
+	c.Redact.Rules = nil
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+	c.Redact.Rules = append(slices.Clip(rules), c.Redact.Rules...)
+	return nil
+}
+
+func (c *Config) resolveRoot(flagRoot string) (string, error) {
+	for _, candidate := range []string{flagRoot, os.Getenv(EnvHome), c.Root} {
+		if candidate != "" {
+			return expandPath(candidate)
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	legacy := filepath.Join(home, ".teatime")
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return legacy, nil
+	}
+
+	dir, err := xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "teatime"), nil
+}
+
+// xdgDir returns the directory named by an XDG environment variable,
+// falling back to fallback under the home directory. Relative values are
+// ignored, as the XDG spec requires.
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine home directory: %w", err)
+	}
+	return filepath.Join(home, fallback), nil
+}
+
+// expandPath expands a leading ~ and makes the path absolute.
+func expandPath(p string) (string, error) {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not determine home directory: %w", err)
+		}
+		p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", p, err)
+	}
+	return abs, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadWith writes the global and root config files and loads them.
+func loadWith(t *testing.T, global, root string) (*Config, error) {
+	t.Helper()
+	dir := t.TempDir()
+	rootDir := filepath.Join(dir, "journal")
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	globalPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(globalPath, []byte(global), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if root != "" {
+		if err := os.WriteFile(filepath.Join(rootDir, ".config.yaml"), []byte(root), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return Load(Options{Root: rootDir, ConfigPath: globalPath})
+}
+
+func TestRootConfigRefusesLLMAndRedactOverrides(t *testing.T) {
+	global := "llm:\n  endpoint: http://localhost:11434/v1\n  model: llama3.1\n"
+	tests := []struct {
+		root    string
+		refused string // empty means the file loads
+	}{
+		{"llm:\n  endpoint: https://example.com/v1\n", "llm.endpoint"},
+		{"llm:\n  api_key_env: HOME\n", "llm.api_key_env"},
+		{"redact:\n  secrets: false\n", "redact.secrets"},
+		{"redact:\n  secrets: true\n", ""},
+		{"llm:\n  model: qwen2.5\n", ""},
+	}
+	for _, tt := range tests {
+		cfg, err := loadWith(t, global, tt.root)
+		if tt.refused == "" {
+			if err != nil {
+				t.Errorf("%q: %v", tt.root, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.refused) {
+			t.Errorf("%q: got %v, want an error naming %s", tt.root, err, tt.refused)
+			continue
+		}
+		if cfg != nil {
+			t.Errorf("%q: got a config along with the error", tt.root)
+		}
+	}
+
+	cfg, err := loadWith(t, global, "llm:\n  model: qwen2.5\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LLM.Endpoint != "http://localhost:11434/v1" || cfg.LLM.Model != "qwen2.5" || !cfg.Redact.Secrets {
+		t.Errorf("got %+v, %+v", cfg.LLM, cfg.Redact)
+	}
+}
+
+func TestRootConfigAddsRedactRules(t *testing.T) {
+	global := "redact:\n  rules:\n    - name: customer\n      pattern: acme\n"
+	root := "redact:\n  rules:\n    - name: project\n      pattern: bluebird\n"
+	cfg, err := loadWith(t, global, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range cfg.Redact.Rules {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, ","); got != "customer,project" {
+		t.Errorf("got rules %s, want customer,project", got)
+	}
+}
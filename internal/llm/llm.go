@@ -0,0 +1,110 @@
+// Package llm talks to the language model that drafts summaries.
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gabrielfornes/teatime/internal/config"
+)
+
+// ErrNotConfigured is returned by New when the config names no endpoint
+// or model.
+var ErrNotConfigured = errors.New("no LLM configured: set llm.endpoint and llm.model in the config file")
+
+// Message is one turn of a chat.
+type Message struct {
+	Role    string `json:"role"` // "system", "user" or "assistant"
+	Content string `json:"content"`
+}
+
+// Delta is the next piece of a streamed reply. A Delta with Err set ends
+// the stream.
+type Delta struct {
+	Text string
+	Err  error
+}
+
+// Provider sends a chat to a model and streams back its reply.
+type Provider interface {
+	// Stream starts a reply to messages. The returned channel yields the
+	// reply as it is generated and is closed when it is complete, after a
+	// Delta carrying the error if it failed. Cancelling ctx stops the
+	// stream.
+	Stream(ctx context.Context, messages []Message) (<-chan Delta, error)
+}
+
+// New returns the provider described by cfg.
+func New(cfg config.LLMConfig) (Provider, error) {
+	if cfg.Endpoint == "" || cfg.Model == "" {
+		return nil, ErrNotConfigured
+	}
+	return NewOpenAI(cfg.Endpoint, cfg.Model, cfg.APIKey()), nil
+}
+
+// DefaultSummaryPrompt is the instruction sent with the reference content
+// when the config sets no prompt of its own.
+const DefaultSummaryPrompt = `You help keep a work journal. Below are the entries for a period, oldest first.
+Distill them into a concise summary in markdown: the main accomplishments, decisions and open threads,
+as short bullet points grouped under a few headings. Keep names, numbers and dates exact,
+do not invent anything, and reply with the summary only.`
+
+// SummaryMessages builds the chat that asks for a summary of reference,
+// the entries that make up period (e.g. "Weekly Notes 2025-W03"). An empty
+// prompt uses DefaultSummaryPrompt.
+func SummaryMessages(prompt, period, reference string) []Message {
+	if strings.TrimSpace(prompt) == "" {
+		prompt = DefaultSummaryPrompt
+	}
+	return []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: "Summarize " + period + ":\n\n" + reference},
+	}
+}
+
+// Filter rewrites text on its way to a model, e.g. to redact secrets.
+type Filter func(string) string
+
+// FilterMessages returns messages with every content passed through f.
+func FilterMessages(messages []Message, f Filter) []Message {
+	out := make([]Message, len(messages))
+	for i, m := range messages {
+		out[i] = Message{Role: m.Role, Content: f(m.Content)}
+	}
+	return out
+}
+
+// WithFilter returns a Provider that passes every message through f before
+// sending it.
+func WithFilter(p Provider, f Filter) Provider {
+	return filteredProvider{p, f}
+}
+
+type filteredProvider struct {
+	Provider
+	filter Filter
+}
+
+func (p filteredProvider) Stream(ctx context.Context, messages []Message) (<-chan Delta, error) {
+	return p.Provider.Stream(ctx, FilterMessages(messages, p.filter))
+}
+
+// EmbedderWithFilter returns an Embedder that passes every text through f
+// before embedding it.
+func EmbedderWithFilter(e Embedder, f Filter) Embedder {
+	return filteredEmbedder{e, f}
+}
+
+type filteredEmbedder struct {
+	Embedder
+	filter Filter
+}
+
+func (e filteredEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	filtered := make([]string, len(texts))
+	for i, t := range texts {
+		filtered[i] = e.filter(t)
+	}
+	return e.Embedder.Embed(ctx, filtered)
+}
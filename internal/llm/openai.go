@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAI is a Provider for any server that speaks the OpenAI chat
+// completions API, including Ollama, llama.cpp and vLLM.
+type OpenAI struct {
+	Endpoint string // base URL, e.g. http://localhost:11434/v1
+	Model    string
+	APIKey   string // sent as a bearer token if set
+	Client   *http.Client
+}
+
+// NewOpenAI returns a provider for the chat completions API at endpoint.
+func NewOpenAI(endpoint, model, apiKey string) *OpenAI {
+	return &OpenAI{
+		Endpoint: strings.TrimRight(endpoint, "/"),
+		Model:    model,
+		APIKey:   apiKey,
+		Client:   http.DefaultClient,
+	}
+}
+
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+// chatChunk is one server-sent event of a streamed completion.
+type chatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Stream implements Provider.
+func (o *OpenAI) Stream(ctx context.Context, messages []Message) (<-chan Delta, error) {
+	body, err := json.Marshal(chatRequest{Model: o.Model, Messages: messages, Stream: true})
+	if err != nil {
+		return nil, fmt.Errorf("could not encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.Endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach %s: %w", o.Endpoint, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("%s returned %s: %s", o.Endpoint, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	ch := make(chan Delta)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+		send := func(d Delta) bool {
+			select {
+			case ch <- d:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if err := readEvents(resp.Body, func(text string) bool { return send(Delta{Text: text}) }); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			send(Delta{Err: err})
+		}
+	}()
+	return ch, nil
+}
+
+// readEvents parses a server-sent event stream of completion chunks,
+// calling emit with each piece of text until the "[DONE]" event, the end
+// of the stream, or emit returning false.
+func readEvents(r io.Reader, emit func(string) bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue // comments, event names and blank separators
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("could not parse response: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("model error: %s", chunk.Error.Message)
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content != "" && !emit(c.Delta.Content) {
+				return nil
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("could not read response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Package redact scrubs customer names, hostnames and secrets from notes
+// before they leave the machine, whether sent to an LLM or exported.
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gabrielfornes/teatime/internal/config"
+)
+
+// rule is a compiled redaction rule. If the pattern has a group named
+// "secret", only that group is replaced, so "password: hunter2" keeps its
+// key and loses its value.
+type rule struct {
+	name    string
+	re      *regexp.Regexp
+	replace string                  // template for regexp.Expand; "" means "[REDACTED:name]"
+	accept  func(match string) bool // optional extra check on the match
+}
+
+// Redactor applies the configured rules and the built-in secret detector.
+type Redactor struct {
+	rules []rule
+}
+
+// Redaction is one replaced span, as byte offsets into the redacted text.
+type Redaction struct {
+	Rule       string
+	Start, End int
+}
+
+// New compiles the rules in cfg. Rules from the config run before the
+// built-in ones, so a custom replacement wins where both match.
+func New(cfg config.RedactConfig) (*Redactor, error) {
+	r := &Redactor{}
+	for i, rc := range cfg.Rules {
+		name := rc.Name
+		if name == "" {
+			name = "rule " + strconv.Itoa(i+1)
+		}
+		re, err := regexp.Compile(rc.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %w", name, err)
+		}
+		if rc.Pattern == "" || re.MatchString("") {
+			return nil, fmt.Errorf("invalid redaction rule %q: pattern matches empty text", name)
+		}
+		r.rules = append(r.rules, rule{name: name, re: re, replace: rc.Replace})
+	}
+	if cfg.Secrets {
+		r.rules = append(r.rules, secretRules...)
+	}
+	return r, nil
+}
+
+// String returns text with every match redacted.
+func (r *Redactor) String(text string) string {
+	out, _ := r.Redact(text)
+	return out
+}
+
+// Redact returns text with every match replaced, and where the
+// replacements are in the result. Where matches overlap, the one that
+// starts first wins, then the longer one.
+func (r *Redactor) Redact(text string) (string, []Redaction) {
+	type match struct {
+		rule       *rule
+		start, end int // span to replace
+		loc        []int
+	}
+	var matches []match
+	for i := range r.rules {
+		ru := &r.rules[i]
+		secret := ru.re.SubexpIndex("secret")
+		for _, loc := range ru.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if secret > 0 && loc[2*secret] >= 0 {
+				start, end = loc[2*secret], loc[2*secret+1]
+			}
+			if end == start || (ru.accept != nil && !ru.accept(text[start:end])) {
+				continue
+			}
+			matches = append(matches, match{rule: ru, start: start, end: end, loc: loc})
+		}
+	}
+	if len(matches) == 0 {
+		return text, nil
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].start != matches[j].start {
+			return matches[i].start < matches[j].start
+		}
+		return matches[i].end > matches[j].end
+	})
+
+	var b strings.Builder
+	var redactions []Redaction
+	pos := 0
+	for _, m := range matches {
+		if m.start < pos {
+			continue // overlaps a match already replaced
+		}
+		b.WriteString(text[pos:m.start])
+		replacement := "[REDACTED:" + m.rule.name + "]"
+		if m.rule.replace != "" {
+			replacement = string(m.rule.re.ExpandString(nil, m.rule.replace, text, m.loc))
+		}
+		redactions = append(redactions, Redaction{Rule: m.rule.name, Start: b.Len(), End: b.Len() + len(replacement)})
+		b.WriteString(replacement)
+		pos = m.end
+	}
+	b.WriteString(text[pos:])
+	return b.String(), redactions
+}
+
+// Describe summarizes redactions for a preview, e.g.
+// "3 redactions: customer ×2, aws-key".
+func Describe(redactions []Redaction) string {
+	if len(redactions) == 0 {
+		return "nothing redacted"
+	}
+	counts := make(map[string]int)
+	var rules []string
+	for _, r := range redactions {
+		if counts[r.Rule] == 0 {
+			rules = append(rules, r.Rule)
+		}
+		counts[r.Rule]++
+	}
+	parts := make([]string, len(rules))
+	for i, name := range rules {
+		parts[i] = name
+		if n := counts[name]; n > 1 {
+			parts[i] += " ×" + strconv.Itoa(n)
+		}
+	}
+	noun := "redactions"
+	if len(redactions) == 1 {
+		noun = "redaction"
+	}
+	return strconv.Itoa(len(redactions)) + " " + noun + ": " + strings.Join(parts, ", ")
+}
+
+// secretRules is the built-in secret detector: well-known token formats,
+// credentials in assignments and URLs, and long random-looking strings.
+var secretRules = []rule{
+	{name: "private-key", re: regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{name: "aws-key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "github-token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{name: "slack-token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{name: "api-key", re: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{name: "bearer-token", re: regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/-]{16,}=*)`)},
+	{name: "url-password", re: regexp.MustCompile(`://[^/\s:@]+:(?P<secret>[^/\s@]+)@`)},
+	{name: "password", re: regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)\b["']?\s*[:=]\s*["']?(?P<secret>[^\s"']{6,})`)},
+	{name: "secret", re: regexp.MustCompile(`\b[A-Za-z0-9+_-]{24,}={0,2}`), accept: looksRandom},
+}
+
+// looksRandom reports whether s reads like a generated key rather than a
+// word or identifier: letters mixed with digits, not plain hex such as a
+// commit hash, and a high Shannon entropy.
+func looksRandom(s string) bool {
+	letters, digits, hex := false, false, true
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			letters = true
+		}
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			hex = false
+		}
+	}
+	if !letters || !digits || hex {
+		return false
+	}
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 4
+}
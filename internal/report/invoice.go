@@ -0,0 +1,138 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// WriteInvoice renders inv to w as markdown or HTML.
+func WriteInvoice(w io.Writer, inv *storage.Invoice, format Format) error {
+	switch format {
+	case FormatMarkdown:
+		return WriteInvoiceMarkdown(w, inv)
+	case FormatHTML:
+		return WriteInvoiceHTML(w, inv)
+	}
+	return fmt.Errorf("invoices can't be written as %s (want markdown or html)", format)
+}
+
+// WriteInvoiceMarkdown renders inv as a markdown document with one table
+// row per billed day.
+func WriteInvoiceMarkdown(w io.Writer, inv *storage.Invoice) error {
+	fmt.Fprintf(w, "# Invoice %s\n\n", inv.Number)
+	fmt.Fprintf(w, "**Date:** %s  \n", inv.Issued.Format("2006-01-02"))
+	if inv.Client != "" {
+		fmt.Fprintf(w, "**Bill to:** %s  \n", inv.Client)
+	}
+	fmt.Fprintf(w, "**Project:** %s  \n", inv.Title)
+	fmt.Fprintf(w, "**Period:** %s\n\n", monthRange(inv.Month))
+	if len(inv.Items) == 0 {
+		_, err := fmt.Fprintln(w, "No time tracked in this period.")
+		return err
+	}
+
+	fmt.Fprintln(w, "| Date | Description | Hours | Rate | Amount |")
+	fmt.Fprintln(w, "|------|-------------|------:|-----:|-------:|")
+	for _, item := range inv.Items {
+		fmt.Fprintf(w, "| %s | %s | %.2f | %s | %s |\n",
+			item.Date.Format("2006-01-02"), markdownCell(item.Description), item.Hours(),
+			Money(inv.Rate, inv.Currency), Money(inv.Amount(item), inv.Currency))
+	}
+	_, err := fmt.Fprintf(w, "| **Total** | | **%.2f** | | **%s** |\n", inv.TotalHours(), Money(inv.Total(), inv.Currency))
+	return err
+}
+
+// WriteInvoiceHTML renders inv as a standalone HTML page, ready to print
+// to PDF from a browser.
+func WriteInvoiceHTML(w io.Writer, inv *storage.Invoice) error {
+	return invoiceTemplate.Execute(w, struct {
+		*storage.Invoice
+		Period string
+	}{inv, monthRange(inv.Month)})
+}
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": Money,
+	"date":  func(t time.Time) string { return t.Format("2006-01-02") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+  body { font-family: system-ui, sans-serif; color: #222; max-width: 50rem; margin: 3rem auto; padding: 0 1rem; }
+  h1 { font-weight: 600; margin-bottom: 0.25rem; }
+  dl { display: grid; grid-template-columns: max-content auto; gap: 0.25rem 1rem; }
+  dt { color: #666; }
+  dd { margin: 0; }
+  table { width: 100%; border-collapse: collapse; margin-top: 2rem; }
+  th, td { padding: 0.4rem 0.6rem; border-bottom: 1px solid #ddd; text-align: left; vertical-align: top; }
+  .num { text-align: right; white-space: nowrap; }
+  tfoot td { font-weight: 600; border-bottom: none; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<dl>
+  <dt>Date</dt><dd>{{date .Issued}}</dd>
+  {{- if .Client}}
+  <dt>Bill to</dt><dd>{{.Client}}</dd>
+  {{- end}}
+  <dt>Project</dt><dd>{{.Title}}</dd>
+  <dt>Period</dt><dd>{{.Period}}</dd>
+</dl>
+{{- if .Items}}
+<table>
+<thead>
+<tr><th>Date</th><th>Description</th><th class="num">Hours</th><th class="num">Rate</th><th class="num">Amount</th></tr>
+</thead>
+<tbody>
+{{- range .Items}}
+<tr><td>{{date .Date}}</td><td>{{.Description}}</td><td class="num">{{printf "%.2f" .Hours}}</td><td class="num">{{money $.Rate $.Currency}}</td><td class="num">{{money ($.Amount .) $.Currency}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr><td>Total</td><td></td><td class="num">{{printf "%.2f" .TotalHours}}</td><td></td><td class="num">{{money .Total .Currency}}</td></tr>
+</tfoot>
+</table>
+{{- else}}
+<p>No time tracked in this period.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// Money formats an amount with thousands separators and two decimals,
+// followed by the currency if known, e.g. "1,234.50 EUR".
+func Money(amount float64, currency string) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	s = sign + whole + "." + frac
+	if currency != "" {
+		s += " " + currency
+	}
+	return s
+}
+
+// monthRange describes the days of a month, e.g. "Jan 1 – Jan 31, 2025".
+func monthRange(month string) string {
+	start, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return month
+	}
+	end := start.AddDate(0, 1, -1)
+	return start.Format("Jan 2") + " – " + end.Format("Jan 2, 2006")
+}
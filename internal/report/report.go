@@ -0,0 +1,184 @@
+// Package report renders timesheets as terminal tables, CSV and markdown,
+// and invoices as markdown and HTML.
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// Format is an output format for a timesheet or invoice.
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat parses a format name; "md" is accepted for markdown.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "table":
+		return FormatTable, nil
+	case "csv":
+		return FormatCSV, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want table, csv, markdown or html)", s)
+}
+
+// Write renders ts to w in the given format. Every format has one row per
+// day of the period and one column per project, plus totals.
+func Write(w io.Writer, ts *storage.Timesheet, format Format) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, ts)
+	case FormatMarkdown:
+		return WriteMarkdown(w, ts)
+	case FormatHTML:
+		return fmt.Errorf("timesheets can't be written as html (want table, csv or markdown)")
+	default:
+		return WriteTable(w, ts)
+	}
+}
+
+// WriteTable renders ts as an aligned plain-text table.
+func WriteTable(w io.Writer, ts *storage.Timesheet) error {
+	fmt.Fprintf(w, "Timesheet %s (%s)\n\n", ts.Period, periodRange(ts))
+	if len(ts.Projects) == 0 {
+		_, err := fmt.Fprintln(w, "No time tracked in this period.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"Day"}
+	for _, p := range ts.Projects {
+		header = append(header, ts.Title(p))
+	}
+	header = append(header, "Total")
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+
+	for _, day := range ts.Days {
+		row := []string{day.Format("Mon 2006-01-02")}
+		for _, p := range ts.Projects {
+			row = append(row, tableHours(ts.Get(p, day)))
+		}
+		row = append(row, tableHours(ts.DayTotal(day)))
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+
+	total := []string{"Total"}
+	for _, p := range ts.Projects {
+		total = append(total, Hours(ts.ProjectTotal(p)))
+	}
+	total = append(total, Hours(ts.Total()))
+	fmt.Fprintln(tw, strings.Join(total, "\t")+"\t")
+	return tw.Flush()
+}
+
+// WriteCSV renders ts as CSV with ISO dates and decimal hours, ready for
+// a spreadsheet.
+func WriteCSV(w io.Writer, ts *storage.Timesheet) error {
+	cw := csv.NewWriter(w)
+	header := []string{"date"}
+	for _, p := range ts.Projects {
+		header = append(header, ts.Title(p))
+	}
+	header = append(header, "total")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, day := range ts.Days {
+		row := []string{day.Format("2006-01-02")}
+		for _, p := range ts.Projects {
+			row = append(row, Hours(ts.Get(p, day)))
+		}
+		row = append(row, Hours(ts.DayTotal(day)))
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	total := []string{"total"}
+	for _, p := range ts.Projects {
+		total = append(total, Hours(ts.ProjectTotal(p)))
+	}
+	total = append(total, Hours(ts.Total()))
+	if err := cw.Write(total); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown renders ts as a markdown table under a heading.
+func WriteMarkdown(w io.Writer, ts *storage.Timesheet) error {
+	fmt.Fprintf(w, "## Timesheet %s\n\n%s\n\n", ts.Period, periodRange(ts))
+	if len(ts.Projects) == 0 {
+		_, err := fmt.Fprintln(w, "No time tracked in this period.")
+		return err
+	}
+
+	header := "| Day |"
+	align := "|-----|"
+	for _, p := range ts.Projects {
+		header += " " + markdownCell(ts.Title(p)) + " |"
+		align += "----:|"
+	}
+	fmt.Fprintln(w, header+" Total |")
+	fmt.Fprintln(w, align+"----:|")
+
+	for _, day := range ts.Days {
+		row := "| " + day.Format("Mon 2006-01-02") + " |"
+		for _, p := range ts.Projects {
+			row += " " + tableHours(ts.Get(p, day)) + " |"
+		}
+		fmt.Fprintln(w, row+" "+tableHours(ts.DayTotal(day))+" |")
+	}
+
+	total := "| **Total** |"
+	for _, p := range ts.Projects {
+		total += " **" + Hours(ts.ProjectTotal(p)) + "** |"
+	}
+	_, err := fmt.Fprintln(w, total+" **"+Hours(ts.Total())+"** |")
+	return err
+}
+
+// Hours formats a duration as decimal hours with two places, e.g. "1.50".
+func Hours(d time.Duration) string {
+	return fmt.Sprintf("%.2f", d.Hours())
+}
+
+// tableHours is Hours, with "-" for days without tracked time.
+func tableHours(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return Hours(d)
+}
+
+// periodRange describes the days of a timesheet, e.g. "Jan 13 – Jan 19, 2025".
+func periodRange(ts *storage.Timesheet) string {
+	if len(ts.Days) == 0 {
+		return ""
+	}
+	first, last := ts.Days[0], ts.Days[len(ts.Days)-1]
+	return first.Format("Jan 2") + " – " + last.Format("Jan 2, 2006")
+}
+
+// markdownCell escapes the characters that would break a table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
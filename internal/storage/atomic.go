@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic replaces path with data without ever leaving a truncated
+// file behind: the data goes to a temp file in the same directory, is
+// fsynced, and is then renamed over path. An existing file keeps its mode;
+// new files get perm.
+//
+// If anything fails the original file is untouched and the temp file is
+// removed.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	if info, statErr := os.Stat(path); statErr == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temp file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := tmp.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		return fmt.Errorf("partial write (%d of %d bytes), original left untouched: %w", n, len(data), err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("could not flush to disk, original left untouched: %w", err)
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("could not set file mode: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("could not close temp file, original left untouched: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not replace file: %w", err)
+	}
+
+	// Persist the rename itself. Directories can't be opened for syncing on
+	// every platform, so this part is best-effort.
+	if d, dirErr := os.Open(dir); dirErr == nil {
+		if syncErr := d.Sync(); syncErr != nil && !errors.Is(syncErr, os.ErrInvalid) {
+			d.Close()
+			return fmt.Errorf("could not sync directory: %w", syncErr)
+		}
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Draft is editor content autosaved while a note was being edited, so it
+// can be recovered if the session ends without saving.
+type Draft struct {
+	Content string
+	Saved   time.Time
+}
+
+// draftPath returns <root>/.drafts/<project>/<category>/<name>.md.
+func (s *Store) draftPath(project string, category Category, name string) string {
+	return filepath.Join(s.Root, ".drafts", project, string(category), name+".md")
+}
+
+// SaveDraft autosaves the editor content of a note. Drafts belong to the
+// editing session rather than the journal, so they skip the store lock,
+// history and auto-commit.
+func (s *Store) SaveDraft(project string, category Category, name string, content string) error {
+	path := s.draftPath(project, category, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create drafts directory: %w", err)
+	}
+	if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("could not save draft: %w", err)
+	}
+	return nil
+}
+
+// ReadDraft returns the autosaved draft of a note, or nil if there is none.
+func (s *Store) ReadDraft(project string, category Category, name string) (*Draft, error) {
+	path := s.draftPath(project, category, name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read draft: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read draft: %w", err)
+	}
+	return &Draft{Content: string(data), Saved: info.ModTime()}, nil
+}
+
+// DeleteDraft removes the autosaved draft of a note, if any.
+func (s *Store) DeleteDraft(project string, category Category, name string) error {
+	err := os.Remove(s.draftPath(project, category, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not delete draft: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// loggedTimePattern matches a list item that starts with a duration, after
+// an optional "HH:MM" timestamp as written by `teatime log`, such as
+// "- 2h API redesign", "* 1h30m pairing" or "- 14:32 45m code review".
+var loggedTimePattern = regexp.MustCompile(`^\s*[-*+]\s+(?:\d{1,2}:\d{2}\s+)?(\d+(?:\.\d+)?h(?:\d+m)?|\d+m)(?:\s+|:\s*|$)(.*)$`)
+
+// LoggedTime is a duration written at the start of a list item in a note.
+type LoggedTime struct {
+	Duration time.Duration
+	Text     string // the rest of the line, e.g. "API redesign"
+	Line     int    // 1-based line number in the note
+}
+
+// ParseLoggedTime returns every duration written inline in a note's body,
+// skipping code blocks.
+func ParseLoggedTime(content string) []LoggedTime {
+	note, err := ParseNote(content)
+	if err != nil {
+		note = Note{Body: content}
+	}
+
+	offset := strings.Count(note.head, "\n") // lines taken by the frontmatter
+	var entries []LoggedTime
+	for i, line := range proseLines(note.Body) {
+		m := loggedTimePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		d, err := time.ParseDuration(m[1])
+		if err != nil || d <= 0 {
+			continue
+		}
+		entries = append(entries, LoggedTime{Duration: d, Text: strings.TrimSpace(m[2]), Line: offset + i + 1})
+	}
+	return entries
+}
+
+// LoggedTotal returns the time logged in a note: the frontmatter "hours"
+// field if set, which is taken as the day's total, and otherwise the sum of
+// the inline durations.
+func LoggedTotal(content string) time.Duration {
+	if note, err := ParseNote(content); err == nil && note.Hours > 0 {
+		return time.Duration(note.Hours * float64(time.Hour))
+	}
+	var total time.Duration
+	for _, e := range ParseLoggedTime(content) {
+		total += e.Duration
+	}
+	return total
+}
+
+// DayTime returns the time spent on a project on a day from the time
+// timed with the timer and the time logged in the day's note. Logged time
+// is the day's total, timed work included, so it replaces the timer's time
+// rather than adding to it; the same work is never counted twice.
+func DayTime(timed, logged time.Duration) time.Duration {
+	if logged > 0 {
+		return logged
+	}
+	return timed
+}
+
+// loggedOn returns the time logged in a project's daily note for day
+// ("2025-01-15"), or zero if there is no note.
+func (s *Store) loggedOn(project, day string) (time.Duration, error) {
+	content, err := s.ReadNote(project, CategoryDaily, day)
+	if err != nil {
+		return 0, err
+	}
+	return LoggedTotal(content), nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseLoggedTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []LoggedTime
+	}{
+		{"hours", "- 2h API redesign", []LoggedTime{{2 * time.Hour, "API redesign", 1}}},
+		{"minutes", "* 45m code review", []LoggedTime{{45 * time.Minute, "code review", 1}}},
+		{"hours and minutes", "+ 1h30m pairing", []LoggedTime{{90 * time.Minute, "pairing", 1}}},
+		{"fractional hours", "- 1.5h planning", []LoggedTime{{90 * time.Minute, "planning", 1}}},
+		{"after a log timestamp", "- 14:32 45m code review", []LoggedTime{{45 * time.Minute, "code review", 1}}},
+		{"followed by a colon", "- 2h: incident review", []LoggedTime{{2 * time.Hour, "incident review", 1}}},
+		{"duration alone", "- 30m", []LoggedTime{{30 * time.Minute, "", 1}}},
+		{"indented item", "  - 15m standup", []LoggedTime{{15 * time.Minute, "standup", 1}}},
+		{"timestamp without duration", "- 14:32 shipped the fix", nil},
+		{"not a list item", "2h API redesign", nil},
+		{"not at the start of the item", "- spent 2h on it", nil},
+		{"part of a word", "- 2hours on it", nil},
+		{"zero", "- 0m nothing", nil},
+		{"code fence", "```\n- 2h not logged\n```\n- 1h logged", []LoggedTime{{time.Hour, "logged", 4}}},
+		{"tilde fence", "~~~\n- 2h not logged\n~~~", nil},
+		{
+			name:    "line numbers count the frontmatter",
+			content: "---\nmood: focused\n---\n# Today\n- 2h API redesign\n- 14:32 45m code review\n",
+			want: []LoggedTime{
+				{2 * time.Hour, "API redesign", 5},
+				{45 * time.Minute, "code review", 6},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseLoggedTime(tt.content); !slices.Equal(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggedTotal(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    time.Duration
+	}{
+		{"sum of items", "- 2h API redesign\n- 14:32 45m code review\n- 1.5h planning\n", 4*time.Hour + 15*time.Minute},
+		{"frontmatter hours win", "---\nhours: 6\n---\n- 2h API redesign\n", 6 * time.Hour},
+		{"nothing logged", "Quiet day.\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LoggedTotal(tt.content); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter holds the structured fields teatime understands in a note's
+// YAML frontmatter. Any other keys are kept as they are.
+type Frontmatter struct {
+	Tags  []string // e.g. [billing, oncall]; "tags: a, b" is accepted too
+	Hours float64  // time spent, e.g. 2.5
+	Mood  string   // free text, e.g. "tired"
+	Links []string // related URLs or note paths
+}
+
+// IsZero reports whether no field is set.
+func (f Frontmatter) IsZero() bool {
+	return len(f.Tags) == 0 && f.Hours == 0 && f.Mood == "" && len(f.Links) == 0
+}
+
+func (f Frontmatter) equal(o Frontmatter) bool {
+	return slices.Equal(f.Tags, o.Tags) && f.Hours == o.Hours &&
+		f.Mood == o.Mood && slices.Equal(f.Links, o.Links)
+}
+
+// Note is a note split into its frontmatter and markdown body:
+//
+//	---
+//	tags: [billing]
+//	hours: 2.5
+//	---
+//	Body text...
+//
+// Content turns it back into file content. If the frontmatter fields were
+// not changed, the original frontmatter is written back byte for byte;
+// otherwise only the changed keys are rewritten and other keys survive.
+type Note struct {
+	Frontmatter
+	Body string
+
+	head string     // frontmatter block as read, delimiters included
+	node *yaml.Node // parsed frontmatter mapping; nil if the note had none
+	orig Frontmatter
+}
+
+// HasFrontmatter reports whether the note has a frontmatter block.
+func (n Note) HasFrontmatter() bool {
+	return n.node != nil || !n.Frontmatter.IsZero()
+}
+
+// ParseNote splits note content into frontmatter and body. Content without
+// a frontmatter block becomes the body as is, and so does content whose
+// leading "---" block is not a YAML mapping, such as a markdown rule
+// followed by prose. A block that is not valid YAML is an error; the
+// returned Note then holds the whole content as body.
+func ParseNote(content string) (Note, error) {
+	head, body, ok := splitFrontmatter(content)
+	if !ok {
+		return Note{Body: content}, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatterYAML(head)), &doc); err != nil {
+		return Note{Body: content}, fmt.Errorf("could not parse frontmatter: %w", err)
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) > 0 {
+		node = doc.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return Note{Body: content}, nil
+	}
+
+	var raw struct {
+		Tags  yaml.Node `yaml:"tags"`
+		Hours float64   `yaml:"hours"`
+		Mood  string    `yaml:"mood"`
+		Links yaml.Node `yaml:"links"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return Note{Body: content}, fmt.Errorf("could not parse frontmatter: %w", err)
+	}
+	fm := Frontmatter{Hours: raw.Hours, Mood: raw.Mood}
+	var err error
+	if fm.Tags, err = decodeList(&raw.Tags); err != nil {
+		return Note{Body: content}, fmt.Errorf("could not parse frontmatter tags: %w", err)
+	}
+	if fm.Links, err = decodeList(&raw.Links); err != nil {
+		return Note{Body: content}, fmt.Errorf("could not parse frontmatter links: %w", err)
+	}
+
+	return Note{Frontmatter: fm, Body: body, head: head, node: node, orig: fm}, nil
+}
+
+// Content returns the note as file content. It fails rather than drop the
+// frontmatter if the fields can't be encoded.
+func (n Note) Content() (string, error) {
+	if n.node == nil && n.Frontmatter.IsZero() {
+		return n.Body, nil
+	}
+	if n.node != nil && n.Frontmatter.equal(n.orig) {
+		return n.head + n.Body, nil
+	}
+	fm, err := n.encodeFrontmatter()
+	if err != nil {
+		return "", fmt.Errorf("could not encode frontmatter: %w", err)
+	}
+	return "---\n" + fm + "---\n" + n.Body, nil
+}
+
+// encodeFrontmatter writes the fields into (a copy of) the parsed mapping,
+// leaving unknown keys and their order alone.
+func (n Note) encodeFrontmatter() (string, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if n.node != nil {
+		copied := *n.node
+		copied.Content = slices.Clone(n.node.Content)
+		node = &copied
+	}
+	for _, field := range []struct {
+		key    string
+		value  any
+		remove bool
+	}{
+		{"tags", n.Tags, len(n.Tags) == 0},
+		{"hours", n.Hours, n.Hours == 0},
+		{"mood", n.Mood, n.Mood == ""},
+		{"links", n.Links, len(n.Links) == 0},
+	} {
+		if err := setKey(node, field.key, field.value, field.remove); err != nil {
+			return "", err
+		}
+	}
+	if len(node.Content) == 0 {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// setKey sets key to value in a mapping node, or removes it if remove is set.
+func setKey(node *yaml.Node, key string, value any, remove bool) error {
+	idx := -1
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			idx = i
+			break
+		}
+	}
+	if remove {
+		if idx >= 0 {
+			node.Content = slices.Delete(node.Content, idx, idx+2)
+		}
+		return nil
+	}
+
+	v := &yaml.Node{}
+	if err := v.Encode(value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if v.Kind == yaml.SequenceNode {
+		v.Style = yaml.FlowStyle // tags: [a, b]
+	}
+	if idx >= 0 {
+		old := node.Content[idx+1]
+		v.HeadComment, v.LineComment, v.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		node.Content[idx+1] = v
+		return nil
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+	return nil
+}
+
+// decodeList accepts either a YAML sequence or a comma-separated string.
+func decodeList(node *yaml.Node) ([]string, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		var list []string
+		for _, item := range strings.Split(node.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	default:
+		var list []string
+		err := node.Decode(&list)
+		return list, err
+	}
+}
+
+// splitFrontmatter splits content into the frontmatter block (both "---"
+// lines included) and the body. The block must start on the first line
+// and be closed by a "---" or "..." line.
+func splitFrontmatter(content string) (head, body string, ok bool) {
+	first, _, found := strings.Cut(content, "\n")
+	if !found || strings.TrimRight(first, "\r") != "---" {
+		return "", content, false
+	}
+	offset := len(first) + 1
+	for {
+		line, _, found := strings.Cut(content[offset:], "\n")
+		end := offset + len(line)
+		if found {
+			end++ // keep the newline with the block
+		}
+		if l := strings.TrimRight(line, "\r"); l == "---" || l == "..." {
+			return content[:end], content[end:], true
+		}
+		if !found {
+			return "", content, false
+		}
+		offset = end
+	}
+}
+
+// frontmatterYAML strips the delimiter lines from a frontmatter block.
+func frontmatterYAML(head string) string {
+	_, inner, _ := strings.Cut(head, "\n")
+	inner = strings.TrimRight(inner, "\r\n")
+	if i := strings.LastIndex(inner, "\n"); i >= 0 {
+		return inner[:i+1]
+	}
+	return "" // empty block: "---\n---"
+}
+
+// ReadParsedNote reads a note and splits off its frontmatter. The version
+// is the one WriteParsedNote should be given to save the note back. A note
+// that does not exist yields an empty Note.
+func (s *Store) ReadParsedNote(project string, category Category, name string) (Note, NoteVersion, error) {
+	content, version, err := s.ReadNoteVersion(project, category, name)
+	if err != nil {
+		return Note{}, NoteVersion{}, err
+	}
+	note, err := ParseNote(content)
+	return note, version, err
+}
+
+// WriteParsedNote writes a note, frontmatter included, through WriteNote,
+// so it fails with a *ConflictError if the note changed since base.
+func (s *Store) WriteParsedNote(project string, category Category, name string, note Note, base NoteVersion) error {
+	content, err := note.Content()
+	if err != nil {
+		return err
+	}
+	return s.WriteNote(project, category, name, content, base)
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseNoteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		edit    func(*Note)
+		want    string // "" means content, unchanged
+	}{
+		{
+			name:    "no frontmatter",
+			content: "# Today\n\n- fixed invoices\n",
+		},
+		{
+			name:    "unchanged block is kept byte for byte",
+			content: "---\n# written by hand\ntags:   [billing,oncall]  # two of them\nhours: 2.50\nticket: ABC-1\n---\nBody\n",
+		},
+		{
+			name:    "empty block",
+			content: "---\n---\nBody\n",
+		},
+		{
+			name:    "dots close the block",
+			content: "---\nmood: tired\n...\nBody\n",
+		},
+		{
+			name:    "changed field keeps unknown keys in order",
+			content: "---\nticket: ABC-1\nhours: 2\nreviewer: sam\nclient: acme\n---\nBody\n",
+			edit:    func(n *Note) { n.Hours = 3.5 },
+			want:    "---\nticket: ABC-1\nhours: 3.5\nreviewer: sam\nclient: acme\n---\nBody\n",
+		},
+		{
+			name:    "changed field keeps comments",
+			content: "---\n# logged by hand\nhours: 2 # before lunch\nticket: ABC-1 # see jira\n---\nBody\n",
+			edit:    func(n *Note) { n.Hours = 3 },
+			want:    "---\n# logged by hand\nhours: 3 # before lunch\nticket: ABC-1 # see jira\n---\nBody\n",
+		},
+		{
+			name:    "new field is added after the others",
+			content: "---\nticket: ABC-1\n---\nBody\n",
+			edit:    func(n *Note) { n.Tags = []string{"billing", "oncall"} },
+			want:    "---\nticket: ABC-1\ntags: [billing, oncall]\n---\nBody\n",
+		},
+		{
+			name:    "cleared field is removed",
+			content: "---\nmood: tired\nticket: ABC-1\n---\nBody\n",
+			edit:    func(n *Note) { n.Mood = "" },
+			want:    "---\nticket: ABC-1\n---\nBody\n",
+		},
+		{
+			name:    "frontmatter added to a plain note",
+			content: "Body\n",
+			edit:    func(n *Note) { n.Mood = "focused" },
+			want:    "---\nmood: focused\n---\nBody\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note, err := ParseNote(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.edit != nil {
+				tt.edit(&note)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.content
+			}
+			got, err := note.Content()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseNoteFields(t *testing.T) {
+	note, err := ParseNote("---\ntags: billing, oncall\nhours: 2.5\nmood: tired\nlinks:\n  - https://example.com/PR-12\n---\nBody\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Frontmatter{
+		Tags:  []string{"billing", "oncall"},
+		Hours: 2.5,
+		Mood:  "tired",
+		Links: []string{"https://example.com/PR-12"},
+	}
+	if !note.Frontmatter.equal(want) {
+		t.Errorf("got %+v, want %+v", note.Frontmatter, want)
+	}
+	if note.Body != "Body\n" {
+		t.Errorf("body = %q", note.Body)
+	}
+}
+
+func TestParseNoteMarkdownRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"rule without a closing line", "---\n\nNotes from the retro.\n"},
+		{"rule followed by prose and another rule", "---\nNotes from the retro.\n---\nMore notes.\n"},
+		{"rule followed by a list", "---\n- shipped billing\n- fixed invoices\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note, err := ParseNote(tt.content)
+			if err != nil {
+				t.Fatalf("got error %v, want the content as body", err)
+			}
+			if note.HasFrontmatter() || note.Body != tt.content {
+				t.Errorf("got frontmatter %+v and body %q, want body only", note.Frontmatter, note.Body)
+			}
+			if got, err := note.Content(); err != nil || got != tt.content {
+				t.Errorf("Content() = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestParseNoteInvalidYAML(t *testing.T) {
+	content := "---\ntags: [billing\n---\nBody\n"
+	note, err := ParseNote(content)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if note.Body != content || note.HasFrontmatter() {
+		t.Errorf("got %+v, want the whole content as body", note)
+	}
+}
+
+func TestParsedNoteRoundTripOnStore(t *testing.T) {
+	s := newTestStore(t)
+	content := "---\n# logged by hand\nticket: ABC-1 # see jira\nhours: 2\ntags: [billing]\n---\n# Today\n\n- shipped it\n"
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", content, NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+
+	note, base, err := s.ReadParsedNote("alpha", CategoryDaily, "2025-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note.Hours != 2 || len(note.Tags) != 1 || note.Tags[0] != "billing" {
+		t.Fatalf("parsed %+v", note.Frontmatter)
+	}
+	note.Hours = 3.5
+	note.Mood = "focused"
+	if err := s.WriteParsedNote("alpha", CategoryDaily, "2025-01-15", note, base); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-15")
+	want := "---\n# logged by hand\nticket: ABC-1 # see jira\nhours: 3.5\ntags: [billing]\nmood: focused\n---\n# Today\n\n- shipped it\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Saving against the version read before that write is a conflict.
+	err = s.WriteParsedNote("alpha", CategoryDaily, "2025-01-15", note, base)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Errorf("got %v, want a *ConflictError", err)
+	}
+
+	// A note that doesn't exist yet reads as empty and can be created.
+	note, base, err = s.ReadParsedNote("alpha", CategoryDaily, "2025-01-16")
+	if err != nil || base.Exists || note.HasFrontmatter() {
+		t.Fatalf("got %+v, %+v, %v for a missing note", note, base, err)
+	}
+	note.Tags = []string{"oncall"}
+	note.Body = "Paged at night.\n"
+	if err := s.WriteParsedNote("alpha", CategoryDaily, "2025-01-16", note, base); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-16"); got != "---\ntags: [oncall]\n---\nPaged at night.\n" {
+		t.Errorf("got %q for a new note", got)
+	}
+}
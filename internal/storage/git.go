@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGitBatchWindow is how long auto-commit waits for further saves
+// before committing, so a burst of saves becomes a single commit.
+const DefaultGitBatchWindow = 5 * time.Second
+
+// gitIgnored lists teatime's working files, which are kept out of the
+// journal repository.
+var gitIgnored = []string{".index/", ".lock", ".history/", ".trash/", ".timer", ".drafts/"}
+
+// gitCommitter batches changed files and commits them to the git
+// repository at the storage root.
+type gitCommitter struct {
+	store  *Store
+	window time.Duration
+
+	mu      sync.Mutex
+	pending map[string]gitChange // keyed by path relative to the root
+	timer   *time.Timer
+	lastErr error
+}
+
+// gitChange is one pending change and how to describe it in the message.
+type gitChange struct {
+	project string
+	label   string // e.g. "days/2025-01-15"
+}
+
+// EnableGitAutoCommit makes every successful write or delete commit the
+// changed file to a git repository at the storage root, creating the
+// repository if needed. Saves that happen within window of each other are
+// batched into one commit. No remote is required or used.
+func (s *Store) EnableGitAutoCommit(window time.Duration) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git auto-commit is enabled but git was not found: %w", err)
+	}
+	if window <= 0 {
+		window = DefaultGitBatchWindow
+	}
+
+	// Only treat the root as a repository if it is the top level; a journal
+	// that happens to live inside another checkout gets its own repository.
+	top, err := runGit(s.Root, "rev-parse", "--show-toplevel")
+	if err != nil || !samePath(strings.TrimSpace(top), s.Root) {
+		if _, err := runGit(s.Root, "init", "--quiet"); err != nil {
+			return fmt.Errorf("could not initialize git repository: %w", err)
+		}
+	}
+
+	if err := ensureGitIgnore(s.Root); err != nil {
+		return err
+	}
+
+	s.git = &gitCommitter{
+		store:   s,
+		window:  window,
+		pending: make(map[string]gitChange),
+	}
+	return nil
+}
+
+// Close flushes any batched git commits. It should be called before the
+// process exits.
+func (s *Store) Close() error {
+	if s.git == nil {
+		return nil
+	}
+	return s.git.flush()
+}
+
+// recordChange queues a changed or deleted note for auto-commit, if enabled.
+func (s *Store) recordChange(project string, category Category, name string, deleted bool) {
+	if s.git == nil {
+		return
+	}
+	rel := filepath.Join(project, string(category), name+".md")
+	label := string(category) + "/" + name
+	if deleted {
+		label = "delete " + label
+	}
+	s.git.track(rel, gitChange{project: project, label: label})
+}
+
+// recordProjectChange queues a whole project directory for auto-commit.
+func (s *Store) recordProjectChange(project, label string) {
+	if s.git == nil {
+		return
+	}
+	s.git.track(project, gitChange{project: project, label: label})
+}
+
+// recordFileChange queues a single file inside a project, such as its
+// time log, for auto-commit.
+func (s *Store) recordFileChange(project, file, label string) {
+	if s.git == nil {
+		return
+	}
+	s.git.track(filepath.Join(project, file), gitChange{project: project, label: label})
+}
+
+func (g *gitCommitter) track(rel string, change gitChange) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending[rel] = change
+	g.schedule()
+}
+
+// schedule (re)starts the batch window; the caller must hold g.mu.
+func (g *gitCommitter) schedule() {
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	g.timer = time.AfterFunc(g.window, func() {
+		_ = g.flush()
+	})
+}
+
+// flush commits everything pending. Errors are also kept so that Close
+// can report a failure from a background flush. A batch that fails, for
+// example on a leftover index.lock, is queued again for the next window.
+func (g *gitCommitter) flush() error {
+	g.mu.Lock()
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	pending := g.pending
+	g.pending = make(map[string]gitChange)
+	lastErr := g.lastErr
+	g.lastErr = nil
+	g.mu.Unlock()
+
+	if len(pending) == 0 {
+		return lastErr
+	}
+
+	err := g.commit(pending)
+	if err != nil {
+		g.mu.Lock()
+		g.lastErr = err
+		for p, change := range pending {
+			if _, ok := g.pending[p]; !ok { // a change tracked since is newer
+				g.pending[p] = change
+			}
+		}
+		g.schedule()
+		g.mu.Unlock()
+	}
+	return errors.Join(lastErr, err)
+}
+
+func (g *gitCommitter) commit(pending map[string]gitChange) error {
+	// Hold the store lock so a concurrent save or another process's
+	// commit doesn't race with our git index.
+	unlock, err := g.store.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	root := g.store.Root
+	paths := make([]string, 0, len(pending))
+	for p := range pending {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	paths, err = stageablePaths(root, paths)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil // e.g. a note created and deleted again before it was committed
+	}
+	maps.DeleteFunc(pending, func(p string, _ gitChange) bool { return !slices.Contains(paths, p) })
+
+	if _, err := runGit(root, append([]string{"add", "--all", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("git add failed: %w", err)
+	}
+	// Nothing to commit, e.g. a file was saved without changes.
+	if _, err := runGit(root, append([]string{"diff", "--cached", "--quiet", "--"}, paths...)...); err == nil {
+		return nil
+	}
+
+	args := gitIdentityArgs(root)
+	args = append(args, "commit", "--quiet", "-m", commitMessage(pending), "--")
+	args = append(args, paths...)
+	if _, err := runGit(root, args...); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+	return nil
+}
+
+// stageablePaths drops the paths git can't stage: those that neither exist
+// on disk nor are tracked, such as a note created and deleted within one
+// batch window. git add fails on the whole batch if any such path is given.
+func stageablePaths(root string, paths []string) ([]string, error) {
+	var missing []string
+	for _, p := range paths {
+		if _, err := os.Lstat(filepath.Join(root, p)); errors.Is(err, os.ErrNotExist) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		return paths, nil
+	}
+	out, err := runGit(root, append([]string{"ls-files", "-z", "--"}, missing...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %w", err)
+	}
+	tracked := strings.Split(out, "\x00")
+	isTracked := func(p string) bool {
+		p = filepath.ToSlash(p)
+		for _, t := range tracked {
+			if t == p || strings.HasPrefix(t, p+"/") {
+				return true
+			}
+		}
+		return false
+	}
+	kept := paths[:0]
+	for _, p := range paths {
+		if !slices.Contains(missing, p) || isTracked(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+// commitMessage describes the changes grouped by project, e.g.
+// "days/2025-01-15, weeks/2025-W03 (project-alpha)".
+func commitMessage(pending map[string]gitChange) string {
+	byProject := make(map[string][]string)
+	for _, c := range pending {
+		byProject[c.project] = append(byProject[c.project], c.label)
+	}
+	projects := make([]string, 0, len(byProject))
+	for p := range byProject {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+
+	var parts []string
+	for _, p := range projects {
+		labels := byProject[p]
+		sort.Strings(labels)
+		parts = append(parts, strings.Join(labels, ", ")+" ("+p+")")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ensureGitIgnore adds any missing gitIgnored entries to <root>/.gitignore.
+func ensureGitIgnore(root string) error {
+	path := filepath.Join(root, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not read .gitignore: %w", err)
+	}
+	have := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		have[strings.TrimSpace(line)] = true
+	}
+
+	content := string(data)
+	added := false
+	for _, entry := range gitIgnored {
+		if have[entry] {
+			continue
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += entry + "\n"
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("could not write .gitignore: %w", err)
+	}
+	return nil
+}
+
+// gitIdentityArgs supplies a fallback author for repositories where no
+// user.name/user.email is configured, so commits never fail for that reason.
+func gitIdentityArgs(root string) []string {
+	var args []string
+	if out, err := runGit(root, "config", "user.name"); err != nil || strings.TrimSpace(out) == "" {
+		args = append(args, "-c", "user.name=teatime")
+	}
+	if out, err := runGit(root, "config", "user.email"); err != nil || strings.TrimSpace(out) == "" {
+		args = append(args, "-c", "user.email=teatime@localhost")
+	}
+	return args
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// samePath reports whether two paths name the same directory.
+func samePath(a, b string) bool {
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return ra == rb
+}
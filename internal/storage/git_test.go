@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newGitStore returns a store in a temporary directory with auto-commit
+// enabled and a batch window long enough that only Close commits.
+func newGitStore(t *testing.T) *Store {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	s, err := NewAt(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EnableGitAutoCommit(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func gitTracked(t *testing.T, s *Store) []string {
+	t.Helper()
+	out, err := runGit(s.Root, "ls-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(out)
+}
+
+func TestGitCommitSkipsNoteCreatedAndDeletedInOneBatch(t *testing.T) {
+	s := newGitStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "kept\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-16", "gone\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNote("alpha", CategoryDaily, "2025-01-16"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	tracked := gitTracked(t, s)
+	if !slices.Contains(tracked, "alpha/days/2025-01-15.md") {
+		t.Errorf("kept note not committed; tracked: %v", tracked)
+	}
+	if slices.Contains(tracked, "alpha/days/2025-01-16.md") {
+		t.Errorf("deleted note committed; tracked: %v", tracked)
+	}
+}
+
+func TestGitCommitSkipsUncommittedProjectRenamedAway(t *testing.T) {
+	s := newGitStore(t)
+	if err := s.CreateProject("Alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RenameProject("alpha", "Beta"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteNote("beta", CategoryDaily, "2025-01-15", "hello\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	tracked := gitTracked(t, s)
+	if !slices.Contains(tracked, "beta/days/2025-01-15.md") {
+		t.Errorf("note in renamed project not committed; tracked: %v", tracked)
+	}
+}
+
+func TestGitCommitDeletesTrackedNote(t *testing.T) {
+	s := newGitStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "soon gone\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNote("alpha", CategoryDaily, "2025-01-15"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if tracked := gitTracked(t, s); slices.Contains(tracked, "alpha/days/2025-01-15.md") {
+		t.Errorf("deleted note still tracked: %v", tracked)
+	}
+}
+
+func TestGitCommitRetriesFailedBatch(t *testing.T) {
+	s := newGitStore(t)
+	lock := filepath.Join(s.Root, ".git", "index.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "kept\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err == nil {
+		t.Fatal("expected the commit to fail while index.lock exists")
+	}
+
+	if err := os.Remove(lock); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close() // still reports the earlier failure
+	if tracked := gitTracked(t, s); !slices.Contains(tracked, "alpha/days/2025-01-15.md") {
+		t.Errorf("failed batch was not retried; tracked: %v", tracked)
+	}
+}
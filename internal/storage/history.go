@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// historyLimit is the number of revisions kept per note; older ones are pruned.
+const historyLimit = 50
+
+// revisionIDFormat names revision files; it sorts chronologically and is
+// safe in file names on every platform.
+const revisionIDFormat = "2006-01-02T15-04-05.000000"
+
+// Revision is a saved earlier version of a note.
+type Revision struct {
+	ID   string    // e.g. "2025-01-15T10-30-00.000000"
+	Time time.Time // when this version was replaced
+	Size int64
+}
+
+// historyDir returns <root>/.history/<project>/<category>/<name>.
+func (s *Store) historyDir(project string, category Category, name string) string {
+	return filepath.Join(s.Root, ".history", project, string(category), name)
+}
+
+// snapshotNote saves the note's current content as a revision before it is
+// replaced with next. Nothing is saved if the note doesn't exist or the
+// content is unchanged. The caller must hold the store lock.
+func (s *Store) snapshotNote(project string, category Category, name string, next string) error {
+	data, err := os.ReadFile(s.notePath(project, category, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read note for history: %w", err)
+	}
+	if string(data) == next {
+		return nil
+	}
+	return s.saveRevision(project, category, name, data)
+}
+
+// saveRevision saves data as the note's newest revision. The caller must
+// hold the store lock.
+func (s *Store) saveRevision(project string, category Category, name string, data []byte) error {
+	dir := s.historyDir(project, category, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create history directory: %w", err)
+	}
+	id := time.Now().Format(revisionIDFormat)
+	if err := writeFileAtomic(filepath.Join(dir, id+".md"), data, 0644); err != nil {
+		return fmt.Errorf("could not save revision: %w", err)
+	}
+	return s.pruneHistory(dir)
+}
+
+// pruneHistory removes the oldest revisions beyond historyLimit.
+func (s *Store) pruneHistory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("could not read history: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > historyLimit {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return fmt.Errorf("could not prune history: %w", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// ListRevisions returns the saved revisions of a note, newest first.
+func (s *Store) ListRevisions(project string, category Category, name string) ([]Revision, error) {
+	entries, err := os.ReadDir(s.historyDir(project, category, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read history: %w", err)
+	}
+
+	var revs []Revision
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		id := strings.TrimSuffix(e.Name(), ".md")
+		t, err := time.ParseInLocation(revisionIDFormat, id, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		revs = append(revs, Revision{ID: id, Time: t, Size: info.Size()})
+	}
+	sort.Slice(revs, func(i, j int) bool {
+		return revs[i].ID > revs[j].ID
+	})
+	return revs, nil
+}
+
+// ReadRevision returns the content of a saved revision.
+func (s *Store) ReadRevision(project string, category Category, name string, id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid revision %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(s.historyDir(project, category, name), id+".md"))
+	if err != nil {
+		return "", fmt.Errorf("could not read revision: %w", err)
+	}
+	return string(data), nil
+}
+
+// RestoreRevision writes a saved revision back as the note's content,
+// through WriteNote: base is the version of the note the restore was
+// chosen against, and a note changed since is a *ConflictError. The
+// content being replaced is itself kept as a new revision.
+func (s *Store) RestoreRevision(project string, category Category, name string, id string, base NoteVersion) error {
+	content, err := s.ReadRevision(project, category, name, id)
+	if err != nil {
+		return err
+	}
+	return s.WriteNote(project, category, name, content, base)
+}
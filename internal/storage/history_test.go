@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRestoreRevision(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "first\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	_, base, err := s.ReadNoteVersion("alpha", CategoryDaily, "2025-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "second\n", base); err != nil {
+		t.Fatal(err)
+	}
+	revs, err := s.ListRevisions("alpha", CategoryDaily, "2025-01-15")
+	if err != nil || len(revs) != 1 {
+		t.Fatalf("got revisions %v, %v", revs, err)
+	}
+
+	// Restoring against the version read before the second write is stale.
+	err = s.RestoreRevision("alpha", CategoryDaily, "2025-01-15", revs[0].ID, base)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got %v, want a *ConflictError", err)
+	}
+	if got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-15"); got != "second\n" {
+		t.Errorf("stale restore went through: %q", got)
+	}
+
+	if err := s.RestoreRevision("alpha", CategoryDaily, "2025-01-15", revs[0].ID, conflict.Version); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-15"); got != "first\n" {
+		t.Errorf("got %q after restoring", got)
+	}
+	revs, err = s.ListRevisions("alpha", CategoryDaily, "2025-01-15")
+	if err != nil || len(revs) != 2 {
+		t.Fatalf("replaced content not kept as a revision: %v, %v", revs, err)
+	}
+	if old, _ := s.ReadRevision("alpha", CategoryDaily, "2025-01-15", revs[0].ID); old != "second\n" {
+		t.Errorf("newest revision is %q, want the replaced content", old)
+	}
+}
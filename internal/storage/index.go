@@ -0,0 +1,686 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// indexVersion is bumped whenever the on-disk format changes; an index
+// written by another version is discarded and rebuilt.
+const indexVersion = 2
+
+// minTermLength is the shortest word that is indexed. Shorter query words
+// can't narrow the candidates, so they are only checked when scanning lines.
+const minTermLength = 2
+
+// indexDoc is the index entry for one note file.
+type indexDoc struct {
+	Project  string   `json:"project"`
+	Category Category `json:"category"`
+	Name     string   `json:"name"`
+	ModTime  int64    `json:"mtime"` // UnixNano, compared on startup to find stale entries
+	Size     int64    `json:"size"`
+	Terms    []string `json:"terms"`          // distinct lowercase words, sorted
+	Tags     []string `json:"tags,omitempty"` // see ParseTags
+}
+
+func (d *indexDoc) key() string {
+	return docKey(d.Project, d.Category, d.Name)
+}
+
+// indexFile is the JSON layout of .index/index.json.
+type indexFile struct {
+	Version int         `json:"version"`
+	Docs    []*indexDoc `json:"docs"`
+}
+
+// indexChange is one line of .index/changes.jsonl: a note's new entry, or
+// the key of a note that was removed.
+type indexChange struct {
+	Doc    *indexDoc `json:"doc,omitempty"`
+	Remove string    `json:"remove,omitempty"`
+}
+
+// minCompactChanges is the fewest logged changes that are folded back into
+// index.json; below it, or below a quarter of the notes, they are left in
+// changes.jsonl.
+const minCompactChanges = 256
+
+// index is an inverted index over every note in the store, persisted under
+// <root>/.index/. Only the per-note term lists are stored; the postings
+// (term → notes) are rebuilt in memory when the files are loaded.
+//
+// index.json is a snapshot of every entry. A save appends just the entries
+// it changed to changes.jsonl, which is replayed over the snapshot on load
+// and folded into it once it grows, so a write costs the size of the note
+// rather than the size of the journal.
+type index struct {
+	dir string
+
+	mu       sync.Mutex
+	docs     map[string]*indexDoc
+	postings map[string]map[string]bool // term → set of doc keys
+	suffixes []termSuffix               // lookup table for postings; nil when out of date
+	diskMod  time.Time                  // mtime of index.json when last read or written
+	logSize  int64                      // bytes of changes.jsonl applied so far
+	logCount int                        // changes in changes.jsonl
+}
+
+// termSuffix is an entry of the sorted table that finds indexed words by
+// prefix or substring: every suffix of every word, pointing at the word.
+type termSuffix struct {
+	suffix string
+	term   string
+}
+
+func docKey(project string, category Category, name string) string {
+	return project + "/" + string(category) + "/" + name
+}
+
+func (idx *index) snapshotPath() string { return filepath.Join(idx.dir, "index.json") }
+func (idx *index) changesPath() string  { return filepath.Join(idx.dir, "changes.jsonl") }
+
+// index returns the store's index, loading it and bringing it up to date
+// with the notes on disk the first time it is used.
+func (s *Store) index() (*index, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.idx != nil {
+		return s.idx, nil
+	}
+
+	idx := &index{dir: filepath.Join(s.Root, ".index")}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.load(); err != nil {
+		return nil, err
+	}
+	changed, err := idx.sync(s)
+	if err != nil {
+		return nil, err
+	}
+	if changed || idx.diskMod.IsZero() {
+		if err := idx.save(); err != nil {
+			return nil, err
+		}
+	}
+	s.idx = idx
+	return idx, nil
+}
+
+// RebuildIndex discards the search index and rebuilds it from the notes on disk.
+func (s *Store) RebuildIndex() error {
+	s.indexMu.Lock()
+	s.idx = nil
+	s.indexMu.Unlock()
+	for _, name := range []string{"index.json", "changes.jsonl"} {
+		if err := os.Remove(filepath.Join(s.Root, ".index", name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("could not remove index: %w", err)
+		}
+	}
+	_, err := s.index()
+	return err
+}
+
+// indexNote refreshes the index entry for a note after it was written or
+// removed. Index maintenance is best-effort: a failure here must not fail
+// the write itself, and any drift is repaired on the next startup sync.
+func (s *Store) indexNote(project string, category Category, name string) {
+	idx, err := s.index()
+	if err != nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+	if err := idx.refresh(project, category, name, s.notePath(project, category, name)); err != nil {
+		return
+	}
+	_ = idx.record(docKey(project, category, name))
+}
+
+// indexProject adds every note of a project to the index, e.g. after the
+// project was restored from the trash.
+func (s *Store) indexProject(project string) {
+	idx, err := s.index()
+	if err != nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+	var keys []string
+	for _, cat := range AllCategories {
+		entries, err := os.ReadDir(filepath.Join(s.Root, project, string(cat)))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+				continue
+			}
+			name := strings.TrimSuffix(e.Name(), ".md")
+			if idx.refresh(project, cat, name, s.notePath(project, cat, name)) == nil {
+				keys = append(keys, docKey(project, cat, name))
+			}
+		}
+	}
+	_ = idx.record(keys...)
+}
+
+// unindexProject drops every entry of a project from the index.
+func (s *Store) unindexProject(project string) {
+	idx, err := s.index()
+	if err != nil {
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+	var keys []string
+	for key, d := range idx.docs {
+		if d.Project == project {
+			idx.remove(key)
+			keys = append(keys, key)
+		}
+	}
+	_ = idx.record(keys...)
+}
+
+// load reads index.json and replays changes.jsonl over it. An
+// incompatible snapshot leaves the index empty.
+func (idx *index) load() error {
+	idx.docs = make(map[string]*indexDoc)
+	idx.postings = make(map[string]map[string]bool)
+	idx.suffixes = nil
+	idx.diskMod = time.Time{}
+	idx.logSize, idx.logCount = 0, 0
+
+	info, err := os.Stat(idx.snapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return idx.readChanges()
+	}
+	if err != nil {
+		return fmt.Errorf("could not stat index: %w", err)
+	}
+	data, err := os.ReadFile(idx.snapshotPath())
+	if err != nil {
+		return fmt.Errorf("could not read index: %w", err)
+	}
+	idx.diskMod = info.ModTime()
+
+	var f indexFile
+	if err := json.Unmarshal(data, &f); err != nil || f.Version != indexVersion {
+		return nil // corrupt or outdated: start over, sync will fill it in
+	}
+	for _, d := range f.Docs {
+		idx.add(d)
+	}
+	return idx.readChanges()
+}
+
+// readChanges applies the lines of changes.jsonl past those already read.
+// A line still being written is left for next time.
+func (idx *index) readChanges() error {
+	f, err := os.Open(idx.changesPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read index changes: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(idx.logSize, io.SeekStart); err != nil {
+		return fmt.Errorf("could not read index changes: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("could not read index changes: %w", err)
+	}
+	for {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+		if !found {
+			return nil
+		}
+		idx.logSize += int64(len(line)) + 1
+		idx.logCount++
+		data = rest
+
+		var c indexChange
+		if json.Unmarshal(line, &c) != nil {
+			continue // a torn line; sync repairs whatever it described
+		}
+		if c.Doc != nil {
+			idx.remove(c.Doc.key())
+			idx.add(c.Doc)
+		} else if c.Remove != "" {
+			idx.remove(c.Remove)
+		}
+	}
+}
+
+// reload catches up with index files written by another process since we
+// last looked, so our next save doesn't discard its updates.
+func (idx *index) reload() {
+	info, err := os.Stat(idx.snapshotPath())
+	if err != nil || !info.ModTime().Equal(idx.diskMod) {
+		_ = idx.load()
+		return
+	}
+	info, err = os.Stat(idx.changesPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist) && idx.logSize == 0, err == nil && info.Size() == idx.logSize:
+		return
+	case err == nil && info.Size() > idx.logSize:
+		_ = idx.readChanges()
+	default:
+		_ = idx.load() // folded into a new snapshot
+	}
+}
+
+// record appends the current entries of the given notes to changes.jsonl,
+// or, once enough changes have piled up, writes a fresh snapshot.
+func (idx *index) record(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if idx.logCount+len(keys) >= max(minCompactChanges, len(idx.docs)/4) {
+		return idx.save()
+	}
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		c := indexChange{Doc: idx.docs[key]}
+		if c.Doc == nil {
+			c.Remove = key
+		}
+		line, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("could not encode index change: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.MkdirAll(idx.dir, 0755); err != nil {
+		return fmt.Errorf("could not create index directory: %w", err)
+	}
+	f, err := os.OpenFile(idx.changesPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write index changes: %w", err)
+	}
+	n, err := f.Write(buf.Bytes())
+	idx.logSize += int64(n)
+	idx.logCount += len(keys)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write index changes: %w", err)
+	}
+	return nil
+}
+
+// save writes a snapshot of every entry to index.json atomically and
+// empties changes.jsonl, whose changes it now contains.
+func (idx *index) save() error {
+	f := indexFile{Version: indexVersion}
+	for _, d := range idx.docs {
+		f.Docs = append(f.Docs, d)
+	}
+	sort.Slice(f.Docs, func(i, j int) bool {
+		return f.Docs[i].key() < f.Docs[j].key()
+	})
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("could not encode index: %w", err)
+	}
+
+	if err := os.MkdirAll(idx.dir, 0755); err != nil {
+		return fmt.Errorf("could not create index directory: %w", err)
+	}
+	if err := writeFileAtomic(idx.snapshotPath(), data, 0644); err != nil {
+		return fmt.Errorf("could not write index: %w", err)
+	}
+	if info, err := os.Stat(idx.snapshotPath()); err == nil {
+		idx.diskMod = info.ModTime()
+	}
+	// Replaying the old changes over the new snapshot would be harmless, so
+	// a reader that sees both before this removal still ends up correct.
+	if err := os.Remove(idx.changesPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not write index: %w", err)
+	}
+	idx.logSize, idx.logCount = 0, 0
+	return nil
+}
+
+// sync walks every project and category, re-indexing notes whose mtime or
+// size changed and dropping entries for notes that no longer exist.
+// It reports whether anything changed.
+func (idx *index) sync(s *Store) (bool, error) {
+	projects, err := s.ListAllProjects()
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	seen := make(map[string]bool)
+	for _, p := range projects {
+		for _, cat := range AllCategories {
+			dir := filepath.Join(s.Root, p, string(cat))
+			entries, err := os.ReadDir(dir)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("could not read directory %s: %w", dir, err)
+			}
+			for _, e := range entries {
+				if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+					continue
+				}
+				name := strings.TrimSuffix(e.Name(), ".md")
+				key := docKey(p, cat, name)
+				seen[key] = true
+
+				info, err := e.Info()
+				if err != nil {
+					continue
+				}
+				if d, ok := idx.docs[key]; ok && d.ModTime == info.ModTime().UnixNano() && d.Size == info.Size() {
+					continue
+				}
+				if err := idx.refresh(p, cat, name, filepath.Join(dir, e.Name())); err != nil {
+					return false, err
+				}
+				changed = true
+			}
+		}
+	}
+
+	for key := range idx.docs {
+		if !seen[key] {
+			idx.remove(key)
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// refresh re-reads a single note and replaces its entry, or removes the
+// entry if the file is gone.
+func (idx *index) refresh(project string, category Category, name, path string) error {
+	key := docKey(project, category, name)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		idx.remove(key)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not stat note: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read note: %w", err)
+	}
+
+	idx.remove(key)
+	idx.add(&indexDoc{
+		Project:  project,
+		Category: category,
+		Name:     name,
+		ModTime:  info.ModTime().UnixNano(),
+		Size:     info.Size(),
+		Terms:    distinctTerms(string(data)),
+		Tags:     ParseTags(string(data)),
+	})
+	return nil
+}
+
+func (idx *index) add(d *indexDoc) {
+	key := d.key()
+	idx.docs[key] = d
+	for _, t := range d.Terms {
+		set := idx.postings[t]
+		if set == nil {
+			set = make(map[string]bool)
+			idx.postings[t] = set
+			idx.suffixes = nil
+		}
+		set[key] = true
+	}
+}
+
+func (idx *index) remove(key string) {
+	d, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for _, t := range d.Terms {
+		delete(idx.postings[t], key)
+		if len(idx.postings[t]) == 0 {
+			delete(idx.postings, t)
+			idx.suffixes = nil
+		}
+	}
+	delete(idx.docs, key)
+}
+
+// lookup calls fn with every indexed word that contains piece, or with
+// every word that starts with it if prefix is set. The suffix table is
+// rebuilt after words were added or dropped, so a lookup is a binary
+// search rather than a scan of the whole vocabulary.
+func (idx *index) lookup(piece string, prefix bool, fn func(term string)) {
+	if idx.suffixes == nil {
+		idx.suffixes = make([]termSuffix, 0, len(idx.postings))
+		for term := range idx.postings {
+			for i := range term {
+				if len(term)-i < minTermLength {
+					break
+				}
+				idx.suffixes = append(idx.suffixes, termSuffix{suffix: term[i:], term: term})
+			}
+		}
+		sort.Slice(idx.suffixes, func(i, j int) bool {
+			return idx.suffixes[i].suffix < idx.suffixes[j].suffix
+		})
+	}
+
+	seen := make(map[string]bool)
+	i := sort.Search(len(idx.suffixes), func(i int) bool { return idx.suffixes[i].suffix >= piece })
+	for ; i < len(idx.suffixes) && strings.HasPrefix(idx.suffixes[i].suffix, piece); i++ {
+		e := idx.suffixes[i]
+		if prefix && len(e.suffix) != len(e.term) {
+			continue // a suffix from inside the word
+		}
+		if !seen[e.term] {
+			seen[e.term] = true
+			fn(e.term)
+		}
+	}
+}
+
+// candidates returns the notes that may contain a line matching query,
+// ordered the same way a directory walk would visit them. Every query word
+// must appear inside some indexed word of the note, which keeps substring
+// and as-you-type prefix queries working.
+func (idx *index) candidates(project, query string) []*indexDoc {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+
+	var result map[string]bool // nil = unconstrained
+	for _, piece := range terms(query) {
+		if len(piece) < minTermLength {
+			continue
+		}
+		matched := make(map[string]bool)
+		idx.lookup(piece, false, func(term string) {
+			for k := range idx.postings[term] {
+				if result == nil || result[k] {
+					matched[k] = true
+				}
+			}
+		})
+		result = matched
+		if len(result) == 0 {
+			return nil
+		}
+	}
+
+	var docs []*indexDoc
+	for key, d := range idx.docs {
+		if project != "" && d.Project != project {
+			continue
+		}
+		if result == nil || result[key] {
+			docs = append(docs, d)
+		}
+	}
+	sortDocs(docs)
+	return docs
+}
+
+// rank scores every note against the words of question: each word counts
+// once per note that has an indexed word starting with it, weighted by how
+// rare the word is (inverse document frequency), so "migrate" finds
+// "migrated" and common words count for little. Notes sharing no word with
+// the question are left out.
+func (idx *index) rank(words []string) map[*indexDoc]float64 {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+
+	scores := make(map[*indexDoc]float64)
+	n := float64(len(idx.docs))
+	for _, word := range words {
+		matched := make(map[string]bool)
+		idx.lookup(word, true, func(term string) {
+			for k := range idx.postings[term] {
+				matched[k] = true
+			}
+		})
+		if len(matched) == 0 {
+			continue
+		}
+		idf := math.Log(1 + n/float64(len(matched)))
+		for k := range matched {
+			scores[idx.docs[k]] += idf
+		}
+	}
+	return scores
+}
+
+// notes returns the names of all indexed notes in a project's category,
+// most recent first.
+func (idx *index) notes(project string, category Category) []string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+
+	var names []string
+	for _, d := range idx.docs {
+		if d.Project == project && d.Category == category {
+			names = append(names, d.Name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names
+}
+
+// has reports whether a note is in the index.
+func (idx *index) has(project string, category Category, name string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+	_, ok := idx.docs[docKey(project, category, name)]
+	return ok
+}
+
+// tagCounts returns the number of notes carrying each tag.
+func (idx *index) tagCounts() map[string]int {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+
+	counts := make(map[string]int)
+	for _, d := range idx.docs {
+		for _, t := range d.Tags {
+			counts[t]++
+		}
+	}
+	return counts
+}
+
+// withTag returns the notes carrying tag, in no particular order.
+func (idx *index) withTag(tag string) []*indexDoc {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reload()
+
+	var docs []*indexDoc
+	for _, d := range idx.docs {
+		if slices.Contains(d.Tags, tag) {
+			docs = append(docs, d)
+		}
+	}
+	return docs
+}
+
+// sortDocs orders docs by project, then category, then name descending.
+func sortDocs(docs []*indexDoc) {
+	sort.Slice(docs, func(i, j int) bool {
+		a, b := docs[i], docs[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Category != b.Category {
+			return categoryIndex(a.Category) < categoryIndex(b.Category)
+		}
+		return a.Name > b.Name
+	})
+}
+
+// categoryIndex returns the position of a category in AllCategories.
+func categoryIndex(c Category) int {
+	for i, cat := range AllCategories {
+		if cat == c {
+			return i
+		}
+	}
+	return len(AllCategories)
+}
+
+// terms splits text into lowercase words of letters and digits.
+func terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// distinctTerms returns the sorted set of indexable words in text.
+func distinctTerms(text string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range terms(text) {
+		if len(t) < minTermLength || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func searchNames(t *testing.T, s *Store, query string) []string {
+	t.Helper()
+	hits, err := s.Search(query, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, h := range hits {
+		names = append(names, h.Name)
+	}
+	return names
+}
+
+func TestIndexSaveAppendsChange(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "migrated the billing service\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := filepath.Join(s.Root, ".index", "index.json")
+	before, err := os.ReadFile(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-16", "paged about invoices\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.ReadFile(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Error("saving a note rewrote index.json")
+	}
+	changes, err := os.ReadFile(filepath.Join(s.Root, ".index", "changes.jsonl"))
+	if err != nil || len(changes) == 0 {
+		t.Fatalf("no change logged: %v", err)
+	}
+
+	// A fresh store, as in the next run, replays the change.
+	fresh, err := NewAt(s.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := searchNames(t, fresh, "invoices"); len(got) != 1 || got[0] != "2025-01-16" {
+		t.Errorf("got %v from the replayed index", got)
+	}
+}
+
+func TestIndexSeesChangesFromOtherStore(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "first\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewAt(s.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := searchNames(t, other, "first"); len(got) != 1 {
+		t.Fatalf("got %v", got)
+	}
+
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-16", "second\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteNote("alpha", CategoryDaily, "2025-01-15"); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchNames(t, other, "second"); len(got) != 1 || got[0] != "2025-01-16" {
+		t.Errorf("other store missed a new note: %v", got)
+	}
+	if got := searchNames(t, other, "first"); len(got) != 0 {
+		t.Errorf("other store still finds a deleted note: %v", got)
+	}
+}
+
+func TestIndexCompactsChanges(t *testing.T) {
+	s := newTestStore(t)
+	for i := range minCompactChanges + 1 {
+		name := fmt.Sprintf("2025-01-%03d", i)
+		if err := s.WriteNote("alpha", CategoryDaily, name, fmt.Sprintf("entry%d\n", i), NoteVersion{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idx, err := s.index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.logCount >= minCompactChanges {
+		t.Errorf("%d changes left uncompacted", idx.logCount)
+	}
+
+	fresh, err := NewAt(s.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, minCompactChanges} {
+		if got := searchNames(t, fresh, fmt.Sprintf("entry%d", i)); len(got) != 1 {
+			t.Errorf("entry%d: got %v after compaction", i, got)
+		}
+	}
+}
+
+func TestIndexLookup(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "migrated the billing service\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-16", "rated the talk\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"migr", 1},  // prefix
+		{"grat", 1},  // inside a word
+		{"rated", 2}, // whole word and suffix of another
+		{"bill serv", 1},
+		{"zebra", 0},
+	}
+	for _, tt := range tests {
+		if got := searchNames(t, s, tt.query); len(got) != tt.want {
+			t.Errorf("%q: got %v, want %d notes", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRankNotesMatchesWordPrefixes(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "migrated the billing service\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-16", "rated the talk\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	for question, want := range map[string]string{
+		"when did we migrate billing?": "2025-01-15",
+		"who rated it?":                "2025-01-16",
+	} {
+		notes, err := s.RankNotes(question, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(notes) != 1 || notes[0].Name != want {
+			t.Errorf("%q: got %+v, want only %s", question, notes, want)
+		}
+	}
+}
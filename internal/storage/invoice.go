@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// descriptionPrefixPattern matches what precedes the text of a note line:
+// list markers and the "HH:MM" stamp written by `teatime log`.
+var descriptionPrefixPattern = regexp.MustCompile(`^[\s*+-]*(?:\d{1,2}:\d{2}\s+)?`)
+
+// Invoice bills a project's client for the time spent over a month.
+type Invoice struct {
+	Number   string    // e.g. "alpha-2025-01"
+	Issued   time.Time // date the invoice is made out
+	Project  string
+	Title    string // project display name
+	Client   string
+	Month    string // e.g. "2025-01"
+	Rate     float64
+	Currency string
+	Items    []InvoiceItem // one per day with time, in order
+}
+
+// InvoiceItem is one billed day.
+type InvoiceItem struct {
+	Date        time.Time
+	Description string // what was done, from the day's note and timer sessions
+	Time        time.Duration
+}
+
+// Hours returns the billed hours, rounded to two decimals so that the
+// amounts add up to what the invoice shows.
+func (i InvoiceItem) Hours() float64 {
+	return math.Round(i.Time.Hours()*100) / 100
+}
+
+// Amount returns what an item costs at the invoice's rate.
+func (inv *Invoice) Amount(item InvoiceItem) float64 {
+	return math.Round(item.Hours()*inv.Rate*100) / 100
+}
+
+// TotalHours returns the billed hours over all items.
+func (inv *Invoice) TotalHours() float64 {
+	var total float64
+	for _, item := range inv.Items {
+		total += item.Hours()
+	}
+	return total
+}
+
+// Total returns the amount due.
+func (inv *Invoice) Total() float64 {
+	var total float64
+	for _, item := range inv.Items {
+		total += inv.Amount(item)
+	}
+	return total
+}
+
+// Invoice builds an invoice for a project's month ("2025-01") from its
+// timesheet: each day with time becomes a line item, described by the
+// entries in that day's note and the notes left when stopping the timer.
+func (s *Store) Invoice(project, month string) (*Invoice, error) {
+	if !s.ProjectExists(project) {
+		return nil, fmt.Errorf("project %q does not exist", project)
+	}
+	if _, err := periodDays(CategoryMonthly, month); err != nil {
+		return nil, fmt.Errorf("month %q must look like 2025-01", month)
+	}
+	meta, err := s.ProjectMeta(project)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := s.Timesheet(CategoryMonthly, month, project)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.ListSessions(project)
+	if err != nil {
+		return nil, err
+	}
+	sessionNotes := make(map[string][]string) // day → notes
+	for _, sess := range sessions {
+		if sess.Note != "" {
+			day := sess.Start.Local().Format("2006-01-02")
+			sessionNotes[day] = append(sessionNotes[day], sess.Note)
+		}
+	}
+
+	inv := &Invoice{
+		Number:   project + "-" + month,
+		Issued:   time.Now(),
+		Project:  project,
+		Title:    meta.Title(project),
+		Client:   meta.Client,
+		Month:    month,
+		Rate:     meta.Rate,
+		Currency: meta.Currency,
+	}
+	for _, day := range ts.Days {
+		d := ts.Get(project, day)
+		if d == 0 {
+			continue
+		}
+		name := day.Format("2006-01-02")
+		content, err := s.ReadNote(project, CategoryDaily, name)
+		if err != nil {
+			return nil, err
+		}
+		inv.Items = append(inv.Items, InvoiceItem{
+			Date:        day,
+			Description: describeDay(content, sessionNotes[name]),
+			Time:        d,
+		})
+	}
+	return inv, nil
+}
+
+// describeDay summarizes a day's work for an invoice line: the text of the
+// inline time entries and timer notes, or failing that the first line of
+// the note that isn't a heading. Tags are internal and left out.
+func describeDay(content string, sessionNotes []string) string {
+	seen := make(map[string]bool)
+	var parts []string
+	add := func(s string) {
+		s = strings.Join(strings.Fields(stripTags(s)), " ")
+		if s != "" && !seen[s] {
+			seen[s] = true
+			parts = append(parts, s)
+		}
+	}
+	for _, e := range ParseLoggedTime(content) {
+		add(e.Text)
+	}
+	for _, n := range sessionNotes {
+		add(n)
+	}
+	if len(parts) == 0 {
+		note, err := ParseNote(content)
+		if err != nil {
+			note = Note{Body: content}
+		}
+		for _, line := range proseLines(note.Body) {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			if add(descriptionPrefixPattern.ReplaceAllString(line, "")); len(parts) > 0 {
+				break
+			}
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// stripTags removes inline #tags from s.
+func stripTags(s string) string {
+	return tagPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if r, size := utf8.DecodeRuneInString(m); r != '#' {
+			return m[:size] // keep the character before the tag
+		}
+		return ""
+	})
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultLockTimeout is how long a write waits for another teatime
+// process to release the store lock.
+const DefaultLockTimeout = 5 * time.Second
+
+// lockPollInterval is how often a blocked write retries the lock.
+const lockPollInterval = 50 * time.Millisecond
+
+// ErrLockTimeout is returned when the store lock could not be taken in time.
+var ErrLockTimeout = errors.New("timed out waiting for the store lock")
+
+// lock serializes a write path against other goroutines and, through an
+// advisory lock on <root>/.lock, against other teatime processes such as a
+// `teatime log` cron job running next to the TUI. The returned func
+// releases both.
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+
+	path := filepath.Join(s.Root, ".lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("could not open lock file: %w", err)
+	}
+
+	timeout := s.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			s.mu.Unlock()
+			return nil, fmt.Errorf("could not lock %s: %w", path, err)
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			s.mu.Unlock()
+			return nil, fmt.Errorf("%w: another teatime process held %s for more than %s", ErrLockTimeout, path, timeout)
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+		s.mu.Unlock()
+	}, nil
+}
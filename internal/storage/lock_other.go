@@ -0,0 +1,13 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// tryLockFile is a no-op where flock is unavailable; writes are then only
+// serialized within the current process.
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(f *os.File) {}
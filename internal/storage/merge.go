@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+)
+
+// maxDiffCells bounds the LCS table size; larger inputs are treated as
+// having nothing in common rather than using unbounded memory.
+const maxDiffCells = 16 << 20
+
+// DiffOp is the kind of change a DiffLine represents.
+type DiffOp int
+
+const (
+	DiffEqual  DiffOp = iota // line is in both texts
+	DiffDelete               // line is only in the text before
+	DiffInsert               // line is only in the text after
+)
+
+// DiffLine is one line of a line-by-line diff.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffLines returns a line diff turning before into after.
+func DiffLines(before, after string) []DiffLine {
+	a, b := splitLines(before), splitLines(after)
+	match := matchLines(a, b)
+
+	var out []DiffLine
+	j := 0
+	for i, line := range a {
+		if match[i] < 0 {
+			out = append(out, DiffLine{Op: DiffDelete, Text: line})
+			continue
+		}
+		for ; j < match[i]; j++ {
+			out = append(out, DiffLine{Op: DiffInsert, Text: b[j]})
+		}
+		out = append(out, DiffLine{Op: DiffEqual, Text: line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		out = append(out, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+	return out
+}
+
+// Merge3 merges two edits of the same base text line by line, the way
+// diff3 does. Regions changed on only one side are taken from that side;
+// regions changed differently on both sides are wrapped in conflict
+// markers. It reports whether any conflicts were written.
+func Merge3(base, mine, theirs string) (string, bool) {
+	b, m, t := splitLines(base), splitLines(mine), splitLines(theirs)
+	bm := matchLines(b, m)
+	bt := matchLines(b, t)
+
+	var out []string
+	conflicts := false
+	i, j, k := 0, 0, 0
+	for {
+		// Find the next base line that both sides kept.
+		p := i
+		for p < len(b) && (bm[p] < 0 || bt[p] < 0) {
+			p++
+		}
+		jEnd, kEnd := len(m), len(t)
+		if p < len(b) {
+			jEnd, kEnd = bm[p], bt[p]
+		}
+
+		bc, mc, tc := b[i:p], m[j:jEnd], t[k:kEnd]
+		switch {
+		case slices.Equal(mc, bc):
+			out = append(out, tc...)
+		case slices.Equal(tc, bc), slices.Equal(mc, tc):
+			out = append(out, mc...)
+		default:
+			conflicts = true
+			out = append(out, "<<<<<<< yours")
+			out = append(out, mc...)
+			out = append(out, "=======")
+			out = append(out, tc...)
+			out = append(out, ">>>>>>> on disk")
+		}
+
+		if p >= len(b) {
+			break
+		}
+		out = append(out, b[p])
+		i, j, k = p+1, jEnd+1, kEnd+1
+	}
+
+	merged := strings.Join(out, "\n")
+	if strings.HasSuffix(mine, "\n") || strings.HasSuffix(theirs, "\n") {
+		merged += "\n"
+	}
+	return merged, conflicts
+}
+
+// matchLines computes a longest common subsequence of a and b and returns,
+// for each line of a, the index of its matching line in b or -1.
+func matchLines(a, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxDiffCells {
+		return match
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			match[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
+
+// splitLines splits text into lines without their trailing newlines.
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,105 @@
+package storage
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		mine      string
+		theirs    string
+		want      string
+		conflicts bool
+	}{
+		{
+			name:   "only mine changed",
+			base:   "a\nb\nc\n",
+			mine:   "a\nB\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "only theirs changed",
+			base:   "a\nb\nc\n",
+			mine:   "a\nb\nc\n",
+			theirs: "a\nb\nC\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "same change on both sides",
+			base:   "a\nb\nc\n",
+			mine:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "same line appended on both sides",
+			base:   "a\nb\n",
+			mine:   "a\nb\n- [ ] ship it\n",
+			theirs: "a\nb\n- [ ] ship it\n",
+			want:   "a\nb\n- [ ] ship it\n",
+		},
+		{
+			name:   "changes at start and end",
+			base:   "a\nb\nc\nd\n",
+			mine:   "A\nb\nc\nd\n",
+			theirs: "a\nb\nc\nD\n",
+			want:   "A\nb\nc\nD\n",
+		},
+		{
+			name:   "insert at start and append at end",
+			base:   "a\nb\n",
+			mine:   "# title\na\nb\n",
+			theirs: "a\nb\nlogged from the cli\n",
+			want:   "# title\na\nb\nlogged from the cli\n",
+		},
+		{
+			name:      "different changes to the first line",
+			base:      "a\nb\n",
+			mine:      "mine\nb\n",
+			theirs:    "theirs\nb\n",
+			want:      "<<<<<<< yours\nmine\n=======\ntheirs\n>>>>>>> on disk\nb\n",
+			conflicts: true,
+		},
+		{
+			name:      "different lines appended",
+			base:      "a\n",
+			mine:      "a\nmine\n",
+			theirs:    "a\ntheirs\n",
+			want:      "a\n<<<<<<< yours\nmine\n=======\ntheirs\n>>>>>>> on disk\n",
+			conflicts: true,
+		},
+		{
+			name:   "one side deletes the last line",
+			base:   "a\nb\nc\n",
+			mine:   "a\nb\n",
+			theirs: "A\nb\nc\n",
+			want:   "A\nb\n",
+		},
+		{
+			name:   "empty base",
+			base:   "",
+			mine:   "",
+			theirs: "created on disk\n",
+			want:   "created on disk\n",
+		},
+		{
+			name:   "no trailing newline",
+			base:   "a\nb",
+			mine:   "a\nB",
+			theirs: "a\nb",
+			want:   "a\nB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(tt.base, tt.mine, tt.theirs)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if conflicts != tt.conflicts {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.conflicts)
+			}
+		})
+	}
+}
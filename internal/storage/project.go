@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectMetaFile holds a project's metadata, inside the project directory.
+const projectMetaFile = "project.yaml"
+
+// ProjectStatus says whether a project is in active use.
+type ProjectStatus string
+
+const (
+	ProjectActive   ProjectStatus = "active"
+	ProjectArchived ProjectStatus = "archived"
+)
+
+// ProjectMeta is the contents of a project's project.yaml.
+type ProjectMeta struct {
+	DisplayName string            `yaml:"name"`                  // e.g. "Client ACME — Mobile App"
+	Description string            `yaml:"description,omitempty"` // one line shown under the name
+	Color       string            `yaml:"color,omitempty"`       // hex or ANSI color, e.g. "#f25d94" or "205"
+	Created     string            `yaml:"created,omitempty"`     // YYYY-MM-DD
+	Status      ProjectStatus     `yaml:"status"`
+	Client      string            `yaml:"client,omitempty"`   // billed party on invoices
+	Rate        float64           `yaml:"rate,omitempty"`     // hourly rate, e.g. 90
+	Currency    string            `yaml:"currency,omitempty"` // e.g. "EUR"
+	Settings    map[string]string `yaml:"settings,omitempty"` // free-form per-project settings
+}
+
+// Title returns the display name, falling back to the directory name.
+func (p ProjectMeta) Title(name string) string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return name
+}
+
+// Archived reports whether the project is archived.
+func (p ProjectMeta) Archived() bool {
+	return p.Status == ProjectArchived
+}
+
+// ProjectMeta reads a project's metadata. Projects without a project.yaml
+// get defaults derived from the directory name.
+func (s *Store) ProjectMeta(name string) (ProjectMeta, error) {
+	meta := ProjectMeta{DisplayName: name, Status: ProjectActive}
+	data, err := os.ReadFile(filepath.Join(s.Root, name, projectMetaFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return meta, fmt.Errorf("could not read project metadata: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &meta); err != nil {
+			return meta, fmt.Errorf("could not parse %s: %w", filepath.Join(name, projectMetaFile), err)
+		}
+	}
+	if meta.Status == "" {
+		meta.Status = ProjectActive
+	}
+	return meta, nil
+}
+
+// SaveProjectMeta replaces a project's metadata.
+func (s *Store) SaveProjectMeta(name string, meta ProjectMeta) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !s.ProjectExists(name) {
+		return fmt.Errorf("project %q does not exist", name)
+	}
+	if err := s.writeProjectMeta(name, meta); err != nil {
+		return err
+	}
+	s.recordProjectChange(name, "update project metadata")
+	return nil
+}
+
+// writeProjectMeta writes project.yaml. The caller must hold the store lock.
+func (s *Store) writeProjectMeta(name string, meta ProjectMeta) error {
+	if meta.Status == "" {
+		meta.Status = ProjectActive
+	}
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("could not encode project metadata: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.Root, name, projectMetaFile), data, 0644); err != nil {
+		return fmt.Errorf("could not write project metadata: %w", err)
+	}
+	return nil
+}
+
+// newProjectMeta returns the metadata for a freshly created project.
+func newProjectMeta(displayName string) ProjectMeta {
+	return ProjectMeta{
+		DisplayName: strings.TrimSpace(displayName),
+		Created:     time.Now().Format("2006-01-02"),
+		Status:      ProjectActive,
+	}
+}
+
+// SetProjectArchived archives or unarchives a project. Archived projects
+// keep all their notes but are left out of ListProjects.
+func (s *Store) SetProjectArchived(name string, archived bool) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !s.ProjectExists(name) {
+		return fmt.Errorf("project %q does not exist", name)
+	}
+	meta, err := s.ProjectMeta(name)
+	if err != nil {
+		return err
+	}
+	meta.Status = ProjectActive
+	label := "unarchive project"
+	if archived {
+		meta.Status = ProjectArchived
+		label = "archive project"
+	}
+	if err := s.writeProjectMeta(name, meta); err != nil {
+		return err
+	}
+	s.recordProjectChange(name, label)
+	return nil
+}
+
+// IsArchived reports whether a project is archived.
+func (s *Store) IsArchived(name string) bool {
+	meta, err := s.ProjectMeta(name)
+	return err == nil && meta.Archived()
+}
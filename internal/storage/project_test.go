@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetProjectArchived(t *testing.T) {
+	s := newTestStore(t)
+	for _, name := range []string{"Alpha", "Beta"} {
+		if err := s.CreateProject(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SetProjectArchived("alpha", true); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.Root, "alpha", projectMetaFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "status: archived") {
+		t.Errorf("archive state not in %s:\n%s", projectMetaFile, data)
+	}
+	if !s.IsArchived("alpha") || s.IsArchived("beta") {
+		t.Error("IsArchived disagrees with the metadata")
+	}
+	if got, _ := s.ListProjects(); !slices.Equal(got, []string{"beta"}) {
+		t.Errorf("ListProjects = %v, want only beta", got)
+	}
+	if got, _ := s.ListAllProjects(); !slices.Equal(got, []string{"alpha", "beta"}) {
+		t.Errorf("ListAllProjects = %v", got)
+	}
+
+	if err := s.SetProjectArchived("alpha", false); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsArchived("alpha") {
+		t.Error("still archived after unarchiving")
+	}
+	if got, _ := s.ListProjects(); !slices.Equal(got, []string{"alpha", "beta"}) {
+		t.Errorf("ListProjects = %v after unarchiving", got)
+	}
+}
+
+func TestProjectMeta(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.CreateProject("Client ACME — Mobile App"); err != nil {
+		t.Fatal(err)
+	}
+	name := sanitizeName("Client ACME — Mobile App")
+	meta, err := s.ProjectMeta(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title(name) != "Client ACME — Mobile App" || meta.Status != ProjectActive || meta.Created == "" {
+		t.Errorf("new project metadata = %+v", meta)
+	}
+
+	meta.Description = "React Native rewrite"
+	meta.Settings = map[string]string{"board": "https://linear.app/acme"}
+	if err := s.SaveProjectMeta(name, meta); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.ProjectMeta(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Description != "React Native rewrite" || got.Settings["board"] != "https://linear.app/acme" || got.DisplayName != meta.DisplayName {
+		t.Errorf("got %+v after saving", got)
+	}
+
+	// A project made by hand, without project.yaml, gets defaults.
+	if err := os.MkdirAll(filepath.Join(s.Root, "manual"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if meta, err := s.ProjectMeta("manual"); err != nil || meta.Title("manual") != "manual" || meta.Archived() {
+		t.Errorf("got %+v, %v for a project without metadata", meta, err)
+	}
+}
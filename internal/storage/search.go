@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"bufio"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSearchLimit caps the number of hits returned when no limit is given.
+const DefaultSearchLimit = 200
+
+// SearchHit is a single line that matched a search query.
+type SearchHit struct {
+	Project  string
+	Category Category
+	Name     string
+	Line     int      // 1-based line number within the note
+	Text     string   // the full matching line
+	Matches  [][2]int // byte offsets of each matched term within Text
+}
+
+// Location returns the hit formatted as "project/category/name:line".
+func (h SearchHit) Location() string {
+	return h.Project + "/" + string(h.Category) + "/" + h.Name + ":" + strconv.Itoa(h.Line)
+}
+
+// Query is a compiled search query. A line matches when it contains every
+// whitespace-separated term of the query, case-insensitively.
+type Query struct {
+	terms []*regexp.Regexp
+}
+
+// ParseQuery compiles a search query. An empty query matches nothing.
+func ParseQuery(q string) Query {
+	var query Query
+	for _, term := range strings.Fields(q) {
+		query.terms = append(query.terms, regexp.MustCompile("(?i)"+regexp.QuoteMeta(term)))
+	}
+	return query
+}
+
+// Empty reports whether the query has no terms.
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// MatchLine reports whether line contains every term and returns the
+// byte offsets of all term occurrences, sorted by position.
+func (q Query) MatchLine(line string) ([][2]int, bool) {
+	if q.Empty() {
+		return nil, false
+	}
+	var matches [][2]int
+	for _, re := range q.terms {
+		locs := re.FindAllStringIndex(line, -1)
+		if locs == nil {
+			return nil, false
+		}
+		for _, l := range locs {
+			matches = append(matches, [2]int{l[0], l[1]})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i][0] < matches[j][0]
+	})
+	return matches, true
+}
+
+// Search finds lines matching query across every note of every project and
+// returns at most limit hits (DefaultSearchLimit if limit <= 0). Hits are
+// ordered by project, then category, then note name (most recent first).
+func (s *Store) Search(query string, limit int) ([]SearchHit, error) {
+	return s.SearchProject("", query, limit)
+}
+
+// SearchProject is like Search but only looks in one project.
+// An empty project searches all projects.
+//
+// The search index narrows the notes that need to be read; if the index
+// can't be loaded every note is scanned instead.
+func (s *Store) SearchProject(project string, query string, limit int) ([]SearchHit, error) {
+	q := ParseQuery(query)
+	if q.Empty() {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	idx, err := s.index()
+	if err != nil {
+		return s.scanNotes(project, q, limit)
+	}
+
+	var hits []SearchHit
+	for _, d := range idx.candidates(project, query) {
+		content, err := s.ReadNote(d.Project, d.Category, d.Name)
+		if err != nil {
+			return nil, err
+		}
+		hits = appendHits(hits, q, d.Project, d.Category, d.Name, content, limit)
+		if len(hits) >= limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+// scanNotes searches by reading every note of every project.
+func (s *Store) scanNotes(project string, q Query, limit int) ([]SearchHit, error) {
+	projects := []string{project}
+	if project == "" {
+		var err error
+		if projects, err = s.ListAllProjects(); err != nil {
+			return nil, err
+		}
+	}
+
+	var hits []SearchHit
+	for _, p := range projects {
+		for _, cat := range AllCategories {
+			notes, err := s.ListNotes(p, cat)
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range notes {
+				content, err := s.ReadNote(p, cat, n.Name)
+				if err != nil {
+					return nil, err
+				}
+				hits = appendHits(hits, q, p, cat, n.Name, content, limit)
+				if len(hits) >= limit {
+					return hits, nil
+				}
+			}
+		}
+	}
+	return hits, nil
+}
+
+// appendHits adds every matching line of content to hits, stopping at limit.
+func appendHits(hits []SearchHit, q Query, project string, category Category, name, content string, limit int) []SearchHit {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		matches, ok := q.MatchLine(text)
+		if !ok {
+			continue
+		}
+		hits = append(hits, SearchHit{
+			Project:  project,
+			Category: category,
+			Name:     name,
+			Line:     line,
+			Text:     text,
+			Matches:  matches,
+		})
+		if len(hits) >= limit {
+			break
+		}
+	}
+	return hits
+}
+
+// RankedNote is a note scored by RankNotes.
+type RankedNote struct {
+	Project  string
+	Category Category
+	Name     string
+	Score    float64
+}
+
+// Source returns the note as "project/category/name".
+func (n RankedNote) Source() string {
+	return docKey(n.Project, n.Category, n.Name)
+}
+
+// questionStopwords are words too common in questions to say anything
+// about which notes answer them.
+var questionStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"did": true, "do": true, "does": true, "for": true, "from": true, "had": true, "has": true, "have": true,
+	"how": true, "i": true, "in": true, "is": true, "it": true, "last": true, "me": true, "my": true,
+	"of": true, "on": true, "or": true, "our": true, "the": true, "that": true, "this": true, "to": true,
+	"was": true, "we": true, "were": true, "what": true, "when": true, "where": true, "which": true,
+	"who": true, "why": true, "with": true, "you": true,
+}
+
+// QuestionWords returns the words of a natural-language question that are
+// worth looking up: lowercased, without stopwords or words too short to
+// index.
+func QuestionWords(question string) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, w := range terms(question) {
+		if len(w) < minTermLength || questionStopwords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+// RankNotes returns up to limit notes most relevant to a natural-language
+// question, best first, using the search index. Unlike Search a note
+// doesn't need every word, and rarer words weigh more; ties go to the most
+// recent note.
+func (s *Store) RankNotes(question string, limit int) ([]RankedNote, error) {
+	words := QuestionWords(question)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	idx, err := s.index()
+	if err != nil {
+		return nil, err
+	}
+	var notes []RankedNote
+	for d, score := range idx.rank(words) {
+		notes = append(notes, RankedNote{Project: d.Project, Category: d.Category, Name: d.Name, Score: score})
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		a, b := notes[i], notes[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Name != b.Name {
+			return a.Name > b.Name
+		}
+		return a.Source() < b.Source()
+	})
+	if limit > 0 && len(notes) > limit {
+		notes = notes[:limit]
+	}
+	return notes, nil
+}
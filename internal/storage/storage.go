@@ -1,11 +1,13 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,7 +39,18 @@ var AllCategories = []Category{
 
 // Store handles all file system operations for teatime.
 type Store struct {
-	Root string // ~/.teatime
+	Root string // e.g. ~/.teatime
+
+	// LockTimeout is how long writes wait for another process to release
+	// the store lock (DefaultLockTimeout if zero).
+	LockTimeout time.Duration
+
+	mu sync.Mutex // serializes writes from this process; see lock()
+
+	indexMu sync.Mutex // guards idx
+	idx     *index     // search index, loaded on first use
+
+	git *gitCommitter // nil unless git auto-commit is enabled
 }
 
 // New creates a new Store rooted at ~/.teatime.
@@ -47,7 +60,15 @@ func New() (*Store, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not determine home directory: %w", err)
 	}
-	root := filepath.Join(home, ".teatime")
+	return NewAt(filepath.Join(home, ".teatime"))
+}
+
+// NewAt creates a new Store rooted at the given directory.
+// It ensures the root directory exists.
+func NewAt(root string) (*Store, error) {
+	if root == "" {
+		return nil, fmt.Errorf("storage root cannot be empty")
+	}
 	if err := os.MkdirAll(root, 0755); err != nil {
 		return nil, fmt.Errorf("could not create teatime directory: %w", err)
 	}
@@ -56,28 +77,51 @@ func New() (*Store, error) {
 
 // --- Projects ---
 
-// ListProjects returns the names of all project directories, sorted alphabetically.
+// ListProjects returns the names of all active (non-archived) projects,
+// sorted alphabetically.
 func (s *Store) ListProjects() ([]string, error) {
+	return s.listProjects(false)
+}
+
+// ListAllProjects returns the names of all projects, archived or not,
+// sorted alphabetically.
+func (s *Store) ListAllProjects() ([]string, error) {
+	return s.listProjects(true)
+}
+
+func (s *Store) listProjects(includeArchived bool) ([]string, error) {
 	entries, err := os.ReadDir(s.Root)
 	if err != nil {
 		return nil, fmt.Errorf("could not read teatime directory: %w", err)
 	}
 	var projects []string
 	for _, e := range entries {
-		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
-			projects = append(projects, e.Name())
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if !includeArchived && s.IsArchived(e.Name()) {
+			continue
 		}
+		projects = append(projects, e.Name())
 	}
 	sort.Strings(projects)
 	return projects, nil
 }
 
-// CreateProject creates a new project directory with all category subdirectories.
-func (s *Store) CreateProject(name string) error {
-	name = sanitizeName(name)
+// CreateProject creates a new project directory with all category
+// subdirectories. The name is sanitized for the directory and kept as typed
+// as the project's display name.
+func (s *Store) CreateProject(displayName string) error {
+	name := sanitizeName(displayName)
 	if name == "" {
 		return fmt.Errorf("project name cannot be empty")
 	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	projectDir := filepath.Join(s.Root, name)
 	for _, cat := range AllCategories {
 		dir := filepath.Join(projectDir, string(cat))
@@ -85,16 +129,95 @@ func (s *Store) CreateProject(name string) error {
 			return fmt.Errorf("could not create directory %s: %w", dir, err)
 		}
 	}
-	return nil
+	if _, err := os.Stat(filepath.Join(projectDir, projectMetaFile)); err == nil {
+		return nil
+	}
+	return s.writeProjectMeta(name, newProjectMeta(displayName))
 }
 
-// DeleteProject removes a project directory and all its contents.
+// DeleteProject moves a project directory and all its contents to the trash.
 func (s *Store) DeleteProject(name string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	projectDir := filepath.Join(s.Root, name)
 	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
 		return fmt.Errorf("project %q does not exist", name)
 	}
-	return os.RemoveAll(projectDir)
+	if err := s.moveToTrash(TrashItem{Kind: TrashProject, Project: name, Path: name}); err != nil {
+		return err
+	}
+	if err := s.retargetTimer(name, ""); err != nil {
+		return err
+	}
+	s.unindexProject(name)
+	s.recordProjectChange(name, "delete project")
+	return nil
+}
+
+// RenameProject renames a project, carrying its revision history and
+// drafts along. Like in CreateProject, the directory gets the sanitized
+// name and the display name is kept as typed.
+func (s *Store) RenameProject(oldName, displayName string) error {
+	newName := sanitizeName(displayName)
+	if newName == "" {
+		return fmt.Errorf("project name cannot be empty")
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !s.ProjectExists(oldName) {
+		return fmt.Errorf("project %q does not exist", oldName)
+	}
+	if newName != oldName {
+		if _, err := os.Stat(filepath.Join(s.Root, newName)); err == nil {
+			return fmt.Errorf("project %q already exists", newName)
+		}
+	}
+	meta, err := s.ProjectMeta(oldName)
+	if err != nil {
+		return err
+	}
+	meta.DisplayName = strings.TrimSpace(displayName)
+	if err := s.writeProjectMeta(oldName, meta); err != nil {
+		return err
+	}
+	if newName == oldName {
+		s.recordProjectChange(oldName, "rename project")
+		return nil
+	}
+	if err := os.Rename(filepath.Join(s.Root, oldName), filepath.Join(s.Root, newName)); err != nil {
+		return fmt.Errorf("could not rename project: %w", err)
+	}
+
+	oldHistory := filepath.Join(s.Root, ".history", oldName)
+	if _, err := os.Stat(oldHistory); err == nil {
+		if err := os.Rename(oldHistory, filepath.Join(s.Root, ".history", newName)); err != nil {
+			return fmt.Errorf("could not move project history: %w", err)
+		}
+	}
+	oldDrafts := filepath.Join(s.Root, ".drafts", oldName)
+	if _, err := os.Stat(oldDrafts); err == nil {
+		if err := os.Rename(oldDrafts, filepath.Join(s.Root, ".drafts", newName)); err != nil {
+			return fmt.Errorf("could not move project drafts: %w", err)
+		}
+	}
+	if err := s.retargetTimer(oldName, newName); err != nil {
+		return err
+	}
+
+	s.unindexProject(oldName)
+	s.indexProject(newName)
+	s.recordProjectChange(oldName, "rename to "+newName)
+	s.recordProjectChange(newName, "rename from "+oldName)
+	return nil
 }
 
 // ProjectExists checks whether a project directory exists.
@@ -160,26 +283,185 @@ func (s *Store) ReadNote(project string, category Category, name string) (string
 	return string(data), nil
 }
 
-// WriteNote writes content to a note file, creating it if necessary.
-func (s *Store) WriteNote(project string, category Category, name string, content string) error {
+// WriteNote writes content to a note file, creating it if necessary, but
+// only if the note on disk still matches base: the version returned by
+// ReadNoteVersion when the content being replaced was read, or the zero
+// NoteVersion for a note expected not to exist yet. Otherwise it writes
+// nothing and returns a *ConflictError holding the disk content.
+// The write is atomic: a crash or full disk leaves the previous version intact.
+func (s *Store) WriteNote(project string, category Category, name string, content string, base NoteVersion) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	disk, current, err := readVersioned(s.notePath(project, category, name))
+	if err != nil {
+		return fmt.Errorf("could not read note: %w", err)
+	}
+	if !current.sameContent(base) {
+		return &ConflictError{
+			Project:  project,
+			Category: category,
+			Name:     name,
+			Disk:     disk,
+			Version:  current,
+		}
+	}
+	return s.writeNote(project, category, name, content)
+}
+
+// writeNote does the work of WriteNote; the caller must hold the store lock.
+func (s *Store) writeNote(project string, category Category, name string, content string) error {
 	dir := filepath.Join(s.Root, project, string(category))
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("could not ensure directory exists: %w", err)
 	}
+	if err := s.snapshotNote(project, category, name, content); err != nil {
+		return err
+	}
 	path := s.notePath(project, category, name)
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+	if err := writeFileAtomic(path, []byte(content), 0644); err != nil {
 		return fmt.Errorf("could not write note: %w", err)
 	}
+	s.indexNote(project, category, name)
+	s.recordChange(project, category, name, false)
 	return nil
 }
 
-// DeleteNote removes a note file.
+// AppendToNote appends text to the end of a note. A newline is inserted
+// first if the note does not already end with one. A note that doesn't
+// exist yet starts with its template, if there is one, and a blank line.
+//
+// Unlike a read-modify-write through WriteNote, the text is added with a
+// single O_APPEND write, so it never clobbers content written in between.
+func (s *Store) AppendToNote(project string, category Category, name string, text string) error {
+	return s.appendToNote(project, category, name, text, "\n")
+}
+
+// AppendParagraph appends text to a note like AppendToNote, separated from
+// what is already there by a blank line.
+func (s *Store) AppendParagraph(project string, category Category, name string, text string) error {
+	return s.appendToNote(project, category, name, text, "\n\n")
+}
+
+// appendToNote does the work of AppendToNote and AppendParagraph: sep is
+// what a non-empty note must end with before text is added.
+func (s *Store) appendToNote(project string, category Category, name string, text, sep string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	dir := filepath.Join(s.Root, project, string(category))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not ensure directory exists: %w", err)
+	}
+	path := s.notePath(project, category, name)
+	var prefix string
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		// Checked under the lock, so two processes can't both add the template.
+		if prefix, err = s.NoteTemplate(project, category, name); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open note: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat note: %w", err)
+	}
+	switch {
+	case prefix != "":
+		text = prefix + missingSuffix(prefix, "\n\n") + text
+	case info.Size() > 0:
+		last := make([]byte, min(info.Size(), int64(len(sep))))
+		if _, err := f.ReadAt(last, info.Size()-int64(len(last))); err != nil {
+			return fmt.Errorf("could not read note: %w", err)
+		}
+		text = missingSuffix(string(last), sep) + text
+	}
+	if _, err := f.WriteString(text); err != nil {
+		return fmt.Errorf("could not append to note: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not append to note: %w", err)
+	}
+	s.indexNote(project, category, name)
+	s.recordChange(project, category, name, false)
+	return nil
+}
+
+// missingSuffix returns the newlines to add to text so that it ends with
+// sep, which is made of newlines, e.g. "\n" after "a\n" for sep "\n\n".
+func missingSuffix(text, sep string) string {
+	for i := len(sep); i > 0; i-- {
+		if strings.HasSuffix(text, sep[:i]) {
+			return sep[i:]
+		}
+	}
+	return sep
+}
+
+// NoteEdited records a change made to a note outside teatime, e.g. in
+// $EDITOR: before, the content it had, is kept as a revision, and the note
+// is reindexed and queued for auto-commit. It reports whether the note
+// changed.
+func (s *Store) NoteEdited(project string, category Category, name string, before string) (bool, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(s.notePath(project, category, name))
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("could not read note: %w", err)
+	}
+	if string(data) == before {
+		return false, nil
+	}
+	if before != "" {
+		if err := s.saveRevision(project, category, name, []byte(before)); err != nil {
+			return false, err
+		}
+	}
+	s.indexNote(project, category, name)
+	s.recordChange(project, category, name, false)
+	return true, nil
+}
+
+// DeleteNote moves a note file to the trash.
 func (s *Store) DeleteNote(project string, category Category, name string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	path := s.notePath(project, category, name)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return fmt.Errorf("note %q does not exist", name)
 	}
-	return os.Remove(path)
+	err = s.moveToTrash(TrashItem{
+		Kind:     TrashNote,
+		Project:  project,
+		Category: category,
+		Name:     name,
+		Path:     filepath.Join(project, string(category), name+".md"),
+	})
+	if err != nil {
+		return err
+	}
+	s.indexNote(project, category, name)
+	s.recordChange(project, category, name, true)
+	return nil
 }
 
 // NoteExists checks whether a note file exists.
@@ -198,7 +480,12 @@ func TodayName() string {
 
 // CurrentWeekName returns the current ISO week name (e.g. "2025-W03").
 func CurrentWeekName() string {
-	year, week := time.Now().ISOWeek()
+	return WeekName(time.Now())
+}
+
+// WeekName returns the ISO week name of t (e.g. "2025-W03").
+func WeekName(t time.Time) string {
+	year, week := t.ISOWeek()
 	return fmt.Sprintf("%d-W%02d", year, week)
 }
 
@@ -255,6 +542,24 @@ func CategoryLabel(cat Category) string {
 	}
 }
 
+// ParseCategory converts user input such as "days", "daily" or "day" into a Category.
+func ParseCategory(s string) (Category, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "days", "daily", "day", "d":
+		return CategoryDaily, nil
+	case "weeks", "weekly", "week", "w":
+		return CategoryWeekly, nil
+	case "months", "monthly", "month", "m":
+		return CategoryMonthly, nil
+	case "quarters", "quarterly", "quarter", "q":
+		return CategoryQuarterly, nil
+	case "years", "yearly", "year", "y":
+		return CategoryYearly, nil
+	default:
+		return "", fmt.Errorf("unknown category %q", s)
+	}
+}
+
 // --- Reference Content ---
 
 // GatherReferenceContent collects the content from the level below a summary
@@ -282,14 +587,13 @@ func (s *Store) GatherReferenceContent(project string, category Category, name s
 // gatherDailyForWeek collects all daily entries that fall in the given ISO week.
 // name is like "2025-W33".
 func (s *Store) gatherDailyForWeek(project, name string) (string, error) {
-	monday, err := mondayOfISOWeek(name)
+	days, err := periodDays(CategoryWeekly, name)
 	if err != nil {
-		return "", fmt.Errorf("could not parse week %q: %w", name, err)
+		return "", err
 	}
 
 	var parts []string
-	for i := 0; i < 7; i++ {
-		day := monday.AddDate(0, 0, i)
+	for _, day := range days {
 		dayName := day.Format("2006-01-02")
 		content, err := s.ReadNote(project, CategoryDaily, dayName)
 		if err != nil {
@@ -310,21 +614,17 @@ func (s *Store) gatherDailyForWeek(project, name string) (string, error) {
 // gatherWeeklyForMonth collects all weekly summaries whose ISO week overlaps
 // with the given month. name is like "2025-08".
 func (s *Store) gatherWeeklyForMonth(project, name string) (string, error) {
-	t, err := time.Parse("2006-01", name)
+	days, err := periodDays(CategoryMonthly, name)
 	if err != nil {
-		return "", fmt.Errorf("could not parse month %q: %w", name, err)
+		return "", err
 	}
-	year, month := t.Year(), t.Month()
 
 	// Find all weeks that have at least one day in this month.
 	// Walk every day of the month and collect unique ISO weeks.
 	seen := make(map[string]bool)
 	var weekNames []string
-	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, t.Location()).Day()
-	for d := 1; d <= daysInMonth; d++ {
-		day := time.Date(year, month, d, 0, 0, 0, 0, t.Location())
-		wy, wn := day.ISOWeek()
-		wk := fmt.Sprintf("%d-W%02d", wy, wn)
+	for _, day := range days {
+		wk := WeekName(day)
 		if !seen[wk] {
 			seen[wk] = true
 			weekNames = append(weekNames, wk)
@@ -429,6 +729,87 @@ func mondayOfISOWeek(name string) (time.Time, error) {
 	return monday, nil
 }
 
+// PeriodStart returns the first day of the period a note covers, e.g.
+// the Monday of "2025-W03" or January 1st for "2025".
+func PeriodStart(category Category, name string) (time.Time, error) {
+	var t time.Time
+	var err error
+	switch category {
+	case CategoryDaily:
+		t, err = time.ParseInLocation("2006-01-02", name, time.Local)
+	case CategoryWeekly:
+		t, err = mondayOfISOWeek(name)
+	case CategoryMonthly:
+		t, err = time.ParseInLocation("2006-01", name, time.Local)
+	case CategoryQuarterly:
+		var year, q int
+		if _, err = fmt.Sscanf(name, "%d-Q%d", &year, &q); err == nil {
+			t = time.Date(year, time.Month((q-1)*3+1), 1, 0, 0, 0, 0, time.Local)
+		}
+	case CategoryYearly:
+		t, err = time.ParseInLocation("2006", name, time.Local)
+	default:
+		err = fmt.Errorf("unknown category %q", category)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse %s note name %q: %w", category, name, err)
+	}
+	return t, nil
+}
+
+// periodDays returns every day of a week ("2025-W03", Monday first) or a
+// month ("2025-01"). Week numbers need not be zero-padded ("2025-W3").
+// Only the dates matter: weeks start at local midnight and months at UTC
+// midnight, as they always have when gathering reference content.
+func periodDays(category Category, name string) ([]time.Time, error) {
+	var first time.Time
+	var n int
+	switch category {
+	case CategoryWeekly:
+		monday, err := mondayOfISOWeek(name)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse week %q: %w", name, err)
+		}
+		first, n = monday, 7
+	case CategoryMonthly:
+		t, err := time.Parse("2006-01", name)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse month %q: %w", name, err)
+		}
+		first = t
+		n = time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	default:
+		return nil, fmt.Errorf("%s is not a week or month", category)
+	}
+
+	days := make([]time.Time, n)
+	for i := range days {
+		days[i] = first.AddDate(0, 0, i)
+	}
+	return days, nil
+}
+
+// ShiftPeriod returns the name of the period n periods after name, e.g.
+// ShiftPeriod(CategoryWeekly, "2025-W01", -1) is "2024-W52".
+func ShiftPeriod(category Category, name string, n int) (string, error) {
+	start, err := PeriodStart(category, name)
+	if err != nil {
+		return "", err
+	}
+	switch category {
+	case CategoryDaily:
+		return start.AddDate(0, 0, n).Format("2006-01-02"), nil
+	case CategoryWeekly:
+		return WeekName(start.AddDate(0, 0, 7*n)), nil
+	case CategoryMonthly:
+		return start.AddDate(0, n, 0).Format("2006-01"), nil
+	case CategoryQuarterly:
+		return quarterName(start.AddDate(0, 3*n, 0)), nil
+	default:
+		return start.AddDate(n, 0, 0).Format("2006"), nil
+	}
+}
+
 // --- Reminders ---
 
 // CheckMissingSummaries scans all daily entries for a project and finds every
@@ -436,18 +817,29 @@ func mondayOfISOWeek(name string) (time.Time, error) {
 // summary file. This catches ALL missing summaries, not just the immediately
 // previous period.
 func (s *Store) CheckMissingSummaries(project string) ([]Reminder, error) {
-	notes, err := s.ListNotes(project, CategoryDaily)
-	if err != nil {
-		return nil, err
+	// Answer from the index when it is available rather than walking directories.
+	exists := s.NoteExists
+	var dailies []string
+	if idx, err := s.index(); err == nil {
+		exists = idx.has
+		dailies = idx.notes(project, CategoryDaily)
+	} else {
+		notes, err := s.ListNotes(project, CategoryDaily)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			dailies = append(dailies, n.Name)
+		}
 	}
-	if len(notes) == 0 {
+	if len(dailies) == 0 {
 		return nil, nil
 	}
 
 	// Parse all daily entry dates
 	var dates []time.Time
-	for _, n := range notes {
-		d, err := time.Parse("2006-01-02", n.Name)
+	for _, n := range dailies {
+		d, err := time.Parse("2006-01-02", n)
 		if err != nil {
 			continue
 		}
@@ -495,7 +887,7 @@ func (s *Store) CheckMissingSummaries(project string) ([]Reminder, error) {
 
 	// Check each period for a missing summary
 	for name := range weeks {
-		if !s.NoteExists(project, CategoryWeekly, name) {
+		if !exists(project, CategoryWeekly, name) {
 			reminders = append(reminders, Reminder{
 				Category: CategoryWeekly,
 				Name:     name,
@@ -504,7 +896,7 @@ func (s *Store) CheckMissingSummaries(project string) ([]Reminder, error) {
 		}
 	}
 	for name := range months {
-		if !s.NoteExists(project, CategoryMonthly, name) {
+		if !exists(project, CategoryMonthly, name) {
 			reminders = append(reminders, Reminder{
 				Category: CategoryMonthly,
 				Name:     name,
@@ -513,7 +905,7 @@ func (s *Store) CheckMissingSummaries(project string) ([]Reminder, error) {
 		}
 	}
 	for name := range quarters {
-		if !s.NoteExists(project, CategoryQuarterly, name) {
+		if !exists(project, CategoryQuarterly, name) {
 			reminders = append(reminders, Reminder{
 				Category: CategoryQuarterly,
 				Name:     name,
@@ -522,7 +914,7 @@ func (s *Store) CheckMissingSummaries(project string) ([]Reminder, error) {
 		}
 	}
 	for name := range years {
-		if !s.NoteExists(project, CategoryYearly, name) {
+		if !exists(project, CategoryYearly, name) {
 			reminders = append(reminders, Reminder{
 				Category: CategoryYearly,
 				Name:     name,
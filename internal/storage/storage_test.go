@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := NewAt(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAppendParagraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // "" means the note doesn't exist
+		want     string
+	}{
+		{"new note", "", "added\n"},
+		{"no trailing newline", "first", "first\n\nadded\n"},
+		{"trailing newline", "first\n", "first\n\nadded\n"},
+		{"blank line already", "first\n\n", "first\n\nadded\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			if tt.existing != "" {
+				if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", tt.existing, NoteVersion{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.AppendParagraph("alpha", CategoryDaily, "2025-01-15", "added\n"); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-15")
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendToNoteStartsNewNoteFromTemplateOnce(t *testing.T) {
+	s := newTestStore(t)
+	dir := filepath.Join(s.Root, templatesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "days.md"), []byte("# {{.Period}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.AppendToNote("alpha", CategoryDaily, "2025-01-15", "- entry\n"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-15")
+	if n := strings.Count(got, "# 2025-01-15"); n != 1 {
+		t.Errorf("template added %d times:\n%s", n, got)
+	}
+	if !strings.HasPrefix(got, "# 2025-01-15\n\n- entry\n") {
+		t.Errorf("got %q", got)
+	}
+	if n := strings.Count(got, "- entry\n"); n != 8 {
+		t.Errorf("got %d entries, want 8", n)
+	}
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"iter"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// tagPattern matches inline tags like #oncall or #team-billing. A tag must
+// start with a letter, so "#123" references and "# Heading" are not tags,
+// and must not follow a word character, "/", "&" or "#", which skips URL
+// fragments, HTML entities and "##" headings.
+var tagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_/&#])#(\p{L}[\p{L}\p{N}_-]*)`)
+
+// inlineCodePattern matches `code spans`, whose contents are never tags.
+var inlineCodePattern = regexp.MustCompile("`[^`\n]*`")
+
+// ParseTags returns the distinct tags of a note, lowercased and sorted:
+// the frontmatter "tags" list plus every inline #tag in the body. Tags
+// inside code blocks and code spans are ignored.
+func ParseTags(content string) []string {
+	note, err := ParseNote(content)
+	if err != nil {
+		note = Note{Body: content}
+	}
+
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(tag string) {
+		tag = normalizeTag(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, t := range note.Tags {
+		add(t)
+	}
+
+	for _, line := range proseLines(note.Body) {
+		line = inlineCodePattern.ReplaceAllString(line, "")
+		for _, m := range tagPattern.FindAllStringSubmatch(line, -1) {
+			add(m[1])
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// proseLines yields the lines of a markdown body with their 0-based
+// index, skipping fenced code blocks.
+func proseLines(body string) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		inFence := false
+		for i, line := range strings.Split(body, "\n") {
+			if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+				inFence = !inFence
+				continue
+			}
+			if !inFence && !yield(i, line) {
+				return
+			}
+		}
+	}
+}
+
+// normalizeTag lowercases a tag and strips a leading "#".
+func normalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	return strings.ToLower(strings.TrimRight(tag, "-_"))
+}
+
+// TagCount is a tag and the number of notes carrying it.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// TaggedNote is a note that carries a given tag.
+type TaggedNote struct {
+	Project  string
+	Category Category
+	Name     string
+	Start    time.Time // first day of the period the note covers; zero if the name isn't a date
+}
+
+// Label returns a short description, e.g. "alpha/days/2025-01-15".
+func (t TaggedNote) Label() string {
+	return docKey(t.Project, t.Category, t.Name)
+}
+
+// ListTags returns every tag in the store with the number of notes that
+// carry it, most used first.
+func (s *Store) ListTags() ([]TagCount, error) {
+	idx, err := s.index()
+	if err != nil {
+		return nil, err
+	}
+	var tags []TagCount
+	for tag, n := range idx.tagCounts() {
+		tags = append(tags, TagCount{Tag: tag, Count: n})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// NotesWithTag returns the notes carrying tag in chronological order, by
+// the start of the period each note covers. Notes whose names aren't dates
+// come last.
+func (s *Store) NotesWithTag(tag string) ([]TaggedNote, error) {
+	idx, err := s.index()
+	if err != nil {
+		return nil, err
+	}
+	var notes []TaggedNote
+	for _, d := range idx.withTag(normalizeTag(tag)) {
+		start, _ := PeriodStart(d.Category, d.Name)
+		notes = append(notes, TaggedNote{Project: d.Project, Category: d.Category, Name: d.Name, Start: start})
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		a, b := notes[i], notes[j]
+		if !a.Start.Equal(b.Start) {
+			if a.Start.IsZero() || b.Start.IsZero() {
+				return b.Start.IsZero()
+			}
+			return a.Start.Before(b.Start)
+		}
+		if a.Category != b.Category {
+			return categoryIndex(a.Category) < categoryIndex(b.Category)
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Name < b.Name
+	})
+	return notes, nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"inline tags are lowercased and sorted", "Paged at night #oncall #Incident", []string{"incident", "oncall"}},
+		{"duplicates", "#billing then #Billing again", []string{"billing"}},
+		{"start of line and punctuation", "#oncall: paged (#incident), #db.", []string{"db", "incident", "oncall"}},
+		{"hyphens and underscores", "#team-billing and #on_call-", []string{"on_call", "team-billing"}},
+		{"non-ascii", "#café", []string{"café"}},
+		{"headings", "# Today\n## Done\n###Notes", nil},
+		{"issue numbers", "fixed #123 and #4b", nil},
+		{"url fragment", "see http://x.com/a#frag and https://x.com/#/route", nil},
+		{"html entity", "it&#39;s done &#x27;", nil},
+		{"inside a word", "C#sharp and foo#bar", nil},
+		{"code span", "run `git log #notatag` then #real", []string{"real"}},
+		{"code fence", "```\n#notatag\n```\n#real", []string{"real"}},
+		{"tilde fence", "~~~sh\necho #notatag\n~~~", nil},
+		{"frontmatter tags", "---\ntags: [Billing, oncall]\n---\nBody #billing #incident", []string{"billing", "incident", "oncall"}},
+		{"frontmatter tags as a string", "---\ntags: \"#billing, oncall\"\n---\n", []string{"billing", "oncall"}},
+		{"markdown rule is not frontmatter", "---\nnotes #retro\n---\n", []string{"retro"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTags(tt.content); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templatesDir holds note and prompt templates, at the storage root for
+// every project and inside a project directory for that project alone.
+const templatesDir = ".templates"
+
+// Template file extensions: "weeks.md" prefills new weekly notes and
+// "weeks.prompt" replaces the instructions sent when drafting one.
+const (
+	noteTemplateExt   = ".md"
+	promptTemplateExt = ".prompt"
+)
+
+// TemplateData is what a template can refer to, e.g. {{.Period}} or
+// {{.Start.Format "Jan 2"}}.
+type TemplateData struct {
+	Project   string    // directory name, e.g. "client-acme"
+	Title     string    // display name, e.g. "Client ACME — Mobile App"
+	Category  Category  // e.g. CategoryWeekly
+	Label     string    // e.g. "Weekly Notes"
+	Period    string    // note name, e.g. "2025-W03"
+	Start     time.Time // first day of the period
+	End       time.Time // last day of the period
+	Reference string    // the entries of the level below, as in the reference pane
+}
+
+// NoteTemplate returns the text a new note should start with: the
+// project's or the root's <category>.md template, rendered for the note.
+// It returns "" if there is no template.
+func (s *Store) NoteTemplate(project string, category Category, name string) (string, error) {
+	return s.renderTemplate(project, category, name, noteTemplateExt)
+}
+
+// PromptTemplate returns the instructions for drafting the note with an
+// LLM: the project's or the root's <category>.prompt template, rendered
+// for the note. It returns "" if there is no template.
+func (s *Store) PromptTemplate(project string, category Category, name string) (string, error) {
+	return s.renderTemplate(project, category, name, promptTemplateExt)
+}
+
+func (s *Store) renderTemplate(project string, category Category, name, ext string) (string, error) {
+	path, text, err := s.findTemplate(project, category, ext)
+	if err != nil || path == "" {
+		return "", err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("could not parse template %s: %w", path, err)
+	}
+	data, err := s.TemplateData(project, category, name)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("could not render template %s: %w", path, err)
+	}
+	return b.String(), nil
+}
+
+// findTemplate returns the path and text of the template for category,
+// preferring the project's own. The path is "" if there is none.
+func (s *Store) findTemplate(project string, category Category, ext string) (string, string, error) {
+	file := string(category) + ext
+	for _, dir := range []string{filepath.Join(s.Root, project, templatesDir), filepath.Join(s.Root, templatesDir)} {
+		path := filepath.Join(dir, file)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("could not read template: %w", err)
+		}
+		return path, string(data), nil
+	}
+	return "", "", nil
+}
+
+// TemplateData returns what templates for the named note can refer to.
+func (s *Store) TemplateData(project string, category Category, name string) (TemplateData, error) {
+	meta, err := s.ProjectMeta(project)
+	if err != nil {
+		return TemplateData{}, err
+	}
+	data := TemplateData{
+		Project:  project,
+		Title:    meta.Title(project),
+		Category: category,
+		Label:    CategoryLabel(category),
+		Period:   name,
+	}
+	// A note not named after its period, such as "retro", has no
+	// dates or reference, and templates see zero values.
+	start, err := PeriodStart(category, name)
+	if err != nil {
+		return data, nil
+	}
+	next, _ := ShiftPeriod(category, name, 1)
+	end, _ := PeriodStart(category, next)
+	data.Start, data.End = start, end.AddDate(0, 0, -1)
+	if data.Reference, err = s.GatherReferenceContent(project, category, name); err != nil {
+		return TemplateData{}, err
+	}
+	return data, nil
+}
@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// timeLogFile is the per-project ledger of tracked sessions. Each line is
+// "<start>\t<end>\t<note>" with RFC 3339 timestamps; lines starting with
+// "#" are comments.
+const timeLogFile = "time.log"
+
+// timerFile holds the running timer, if any, at the storage root. Only
+// one timer runs at a time across all projects.
+const timerFile = ".timer"
+
+// ErrNoTimer is returned by StopTimer when no timer is running.
+var ErrNoTimer = errors.New("no timer is running")
+
+// Session is a stretch of time tracked on a project. A running session
+// has a zero End.
+type Session struct {
+	Project string    `json:"project"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end,omitzero"`
+	Note    string    `json:"note,omitempty"`
+}
+
+// Running reports whether the session is still being timed.
+func (s Session) Running() bool {
+	return s.End.IsZero()
+}
+
+// Duration returns the length of the session; a running session counts
+// up to now.
+func (s Session) Duration() time.Duration {
+	if s.Running() {
+		return time.Since(s.Start)
+	}
+	return s.End.Sub(s.Start)
+}
+
+// ActiveTimer returns the running session, or nil if no timer is running.
+func (s *Store) ActiveTimer() (*Session, error) {
+	data, err := os.ReadFile(filepath.Join(s.Root, timerFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read timer: %w", err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", timerFile, err)
+	}
+	return &session, nil
+}
+
+// StartTimer starts timing a project. A timer already running on another
+// project is stopped first and its session is returned.
+func (s *Store) StartTimer(project string) (*Session, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if !s.ProjectExists(project) {
+		return nil, fmt.Errorf("project %q does not exist", project)
+	}
+	active, err := s.ActiveTimer()
+	if err != nil {
+		return nil, err
+	}
+	if active != nil && active.Project == project {
+		return nil, fmt.Errorf("a timer is already running on %s since %s", project, active.Start.Format("15:04"))
+	}
+
+	var stopped *Session
+	if active != nil {
+		session, err := s.stopTimer(*active, "")
+		if err != nil {
+			return nil, err
+		}
+		stopped = &session
+	}
+
+	data, err := json.Marshal(Session{Project: project, Start: time.Now().Truncate(time.Second)})
+	if err != nil {
+		return nil, fmt.Errorf("could not encode timer: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.Root, timerFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("could not start timer: %w", err)
+	}
+	return stopped, nil
+}
+
+// StopTimer stops the running timer and records the session, with an
+// optional note, in the project's time log.
+func (s *Store) StopTimer(note string) (Session, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return Session{}, err
+	}
+	defer unlock()
+
+	active, err := s.ActiveTimer()
+	if err != nil {
+		return Session{}, err
+	}
+	if active == nil {
+		return Session{}, ErrNoTimer
+	}
+	return s.stopTimer(*active, note)
+}
+
+// stopTimer appends a finished session to its project's time log and
+// clears the running timer. The caller must hold the store lock.
+func (s *Store) stopTimer(session Session, note string) (Session, error) {
+	session.End = time.Now().Truncate(time.Second)
+	session.Note = strings.Join(strings.Fields(note), " ") // keep it on one line
+	if err := s.appendSession(session); err != nil {
+		return Session{}, err
+	}
+	if err := os.Remove(filepath.Join(s.Root, timerFile)); err != nil && !os.IsNotExist(err) {
+		return Session{}, fmt.Errorf("could not stop timer: %w", err)
+	}
+	s.recordFileChange(session.Project, timeLogFile, "track "+FormatDuration(session.Duration()))
+	return session, nil
+}
+
+// retargetTimer moves a running timer to a renamed project, or discards
+// it if newName is empty because the project was deleted. The caller must
+// hold the store lock.
+func (s *Store) retargetTimer(oldName, newName string) error {
+	active, err := s.ActiveTimer()
+	if err != nil || active == nil || active.Project != oldName {
+		return err
+	}
+	path := filepath.Join(s.Root, timerFile)
+	if newName == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not stop timer: %w", err)
+		}
+		return nil
+	}
+	active.Project = newName
+	data, err := json.Marshal(active)
+	if err != nil {
+		return fmt.Errorf("could not encode timer: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("could not update timer: %w", err)
+	}
+	return nil
+}
+
+// appendSession adds a line to a project's time log with a single
+// O_APPEND write, like AppendToNote.
+func (s *Store) appendSession(session Session) error {
+	path := filepath.Join(s.Root, session.Project, timeLogFile)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open time log: %w", err)
+	}
+	defer f.Close()
+
+	line := session.Start.Format(time.RFC3339) + "\t" + session.End.Format(time.RFC3339)
+	if session.Note != "" {
+		line += "\t" + session.Note
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("could not write time log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write time log: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns the finished sessions in a project's time log,
+// in the order they were recorded.
+func (s *Store) ListSessions(project string) ([]Session, error) {
+	f, err := os.Open(filepath.Join(s.Root, project, timeLogFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open time log: %w", err)
+	}
+	defer f.Close()
+
+	var sessions []Session
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s/%s:%d: expected <start> <end> [note]", project, timeLogFile, n)
+		}
+		start, err := time.Parse(time.RFC3339, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s:%d: %w", project, timeLogFile, n, err)
+		}
+		end, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s:%d: %w", project, timeLogFile, n, err)
+		}
+		session := Session{Project: project, Start: start, End: end}
+		if len(fields) == 3 {
+			session.Note = fields[2]
+		}
+		sessions = append(sessions, session)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read time log: %w", err)
+	}
+	return sessions, nil
+}
+
+// TrackedByDay returns the time tracked on a project per local day, keyed
+// by daily note name ("2025-01-15"). Sessions that cross midnight are
+// split between the days, and a running timer counts up to now.
+func (s *Store) TrackedByDay(project string) (map[string]time.Duration, error) {
+	sessions, err := s.ListSessions(project)
+	if err != nil {
+		return nil, err
+	}
+	if active, err := s.ActiveTimer(); err == nil && active != nil && active.Project == project {
+		sessions = append(sessions, Session{Project: project, Start: active.Start, End: time.Now()})
+	}
+
+	days := make(map[string]time.Duration)
+	for _, session := range sessions {
+		start, end := session.Start.Local(), session.End.Local()
+		for start.Before(end) {
+			y, m, d := start.Date()
+			midnight := time.Date(y, m, d+1, 0, 0, 0, 0, time.Local)
+			chunkEnd := end
+			if midnight.Before(end) {
+				chunkEnd = midnight
+			}
+			days[start.Format("2006-01-02")] += chunkEnd.Sub(start)
+			start = chunkEnd
+		}
+	}
+	return days, nil
+}
+
+// FormatDuration formats a duration as hours and minutes, e.g. "1h05m" or "45m".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", h, m)
+}
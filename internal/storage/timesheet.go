@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Timesheet is the time spent per project per day over a week or month:
+// the time logged in the daily note (see LoggedTotal) or, on days whose
+// note logs none, the sessions timed with the timer (see DayTime).
+type Timesheet struct {
+	Category Category    // CategoryWeekly or CategoryMonthly
+	Period   string      // e.g. "2025-W03" or "2025-01"
+	Days     []time.Time // every day of the period, in order
+	Projects []string    // projects with tracked time in the period, sorted
+	Titles   map[string]string
+	Hours    map[string]map[string]time.Duration // project → day ("2025-01-15") → time
+}
+
+// Title returns a project's display name.
+func (t *Timesheet) Title(project string) string {
+	if title := t.Titles[project]; title != "" {
+		return title
+	}
+	return project
+}
+
+// Get returns the time tracked on a project on a day.
+func (t *Timesheet) Get(project string, day time.Time) time.Duration {
+	return t.Hours[project][day.Format("2006-01-02")]
+}
+
+// DayTotal returns the time tracked on all projects on a day.
+func (t *Timesheet) DayTotal(day time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range t.Projects {
+		total += t.Get(p, day)
+	}
+	return total
+}
+
+// ProjectTotal returns the time tracked on a project over the period.
+func (t *Timesheet) ProjectTotal(project string) time.Duration {
+	var total time.Duration
+	for _, day := range t.Days {
+		total += t.Get(project, day)
+	}
+	return total
+}
+
+// Total returns the time tracked on all projects over the period.
+func (t *Timesheet) Total() time.Duration {
+	var total time.Duration
+	for _, p := range t.Projects {
+		total += t.ProjectTotal(p)
+	}
+	return total
+}
+
+// ParsePeriod works out whether name is a week ("2025-W03") or a month
+// ("2025-01") and returns the matching category.
+func ParsePeriod(name string) (Category, error) {
+	category := CategoryMonthly
+	if strings.Contains(name, "-W") {
+		category = CategoryWeekly
+	}
+	if _, err := periodDays(category, name); err != nil {
+		return "", fmt.Errorf("period %q must be a week like 2025-W03 or a month like 2025-01", name)
+	}
+	return category, nil
+}
+
+// Timesheet aggregates the time spent on every project, archived ones
+// included, over a week or month. If only is non-empty, just those
+// projects are included.
+func (s *Store) Timesheet(category Category, period string, only ...string) (*Timesheet, error) {
+	days, err := periodDays(category, period)
+	if err != nil {
+		return nil, err
+	}
+	projects := only
+	if len(projects) == 0 {
+		if projects, err = s.ListAllProjects(); err != nil {
+			return nil, err
+		}
+	}
+
+	ts := &Timesheet{
+		Category: category,
+		Period:   period,
+		Days:     days,
+		Titles:   make(map[string]string),
+		Hours:    make(map[string]map[string]time.Duration),
+	}
+	for _, p := range projects {
+		tracked, err := s.TrackedByDay(p)
+		if err != nil {
+			return nil, err
+		}
+		hours := make(map[string]time.Duration)
+		for _, day := range days {
+			name := day.Format("2006-01-02")
+			logged, err := s.loggedOn(p, name)
+			if err != nil {
+				return nil, err
+			}
+			if d := DayTime(tracked[name], logged); d > 0 {
+				hours[name] = d
+			}
+		}
+		if len(hours) == 0 {
+			continue
+		}
+		meta, err := s.ProjectMeta(p)
+		if err != nil {
+			return nil, err
+		}
+		ts.Projects = append(ts.Projects, p)
+		ts.Titles[p] = meta.Title(p)
+		ts.Hours[p] = hours
+	}
+	sort.Strings(ts.Projects)
+	return ts, nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimesheetLoggedTimeReplacesTimer(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.CreateProject("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.January, day, hour, 0, 0, 0, time.Local)
+	}
+	for _, session := range []Session{
+		{Project: "alpha", Start: at(15, 9), End: at(15, 11), Note: "API redesign"},
+		{Project: "alpha", Start: at(16, 9), End: at(16, 10)},
+		{Project: "alpha", Start: at(17, 9), End: at(17, 10)},
+	} {
+		if err := s.appendSession(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	notes := map[string]string{
+		"2025-01-15": "- 2h API redesign\n",         // the timed work, written down too
+		"2025-01-17": "---\nhours: 6\n---\nBusy.\n", // the day's total
+		"2025-01-18": "- 30m code review\n",         // logged without the timer
+	}
+	for name, content := range notes {
+		if err := s.WriteNote("alpha", CategoryDaily, name, content, NoteVersion{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts, err := s.Timesheet(CategoryWeekly, "2025-W03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]time.Duration{15: 2 * time.Hour, 16: time.Hour, 17: 6 * time.Hour, 18: 30 * time.Minute}
+	for day, d := range want {
+		if got := ts.Get("alpha", at(day, 0)); got != d {
+			t.Errorf("Jan %d: got %v, want %v", day, got, d)
+		}
+	}
+	if got := ts.Total(); got != 9*time.Hour+30*time.Minute {
+		t.Errorf("total %v", got)
+	}
+}
+
+func TestDayTime(t *testing.T) {
+	tests := []struct {
+		timed, logged, want time.Duration
+	}{
+		{2 * time.Hour, 0, 2 * time.Hour},
+		{0, 90 * time.Minute, 90 * time.Minute},
+		{2 * time.Hour, 90 * time.Minute, 90 * time.Minute},
+		{0, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := DayTime(tt.timed, tt.logged); got != tt.want {
+			t.Errorf("DayTime(%v, %v) = %v, want %v", tt.timed, tt.logged, got, tt.want)
+		}
+	}
+}
+
+func TestPeriodDays(t *testing.T) {
+	tests := []struct {
+		category    Category
+		name        string
+		first, last string
+		n           int
+	}{
+		{CategoryWeekly, "2025-W03", "2025-01-13", "2025-01-19", 7},
+		{CategoryWeekly, "2025-W3", "2025-01-13", "2025-01-19", 7},
+		{CategoryWeekly, "2025-W01", "2024-12-30", "2025-01-05", 7},
+		{CategoryMonthly, "2025-01", "2025-01-01", "2025-01-31", 31},
+		{CategoryMonthly, "2024-02", "2024-02-01", "2024-02-29", 29},
+	}
+	for _, tt := range tests {
+		days, err := periodDays(tt.category, tt.name)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(days) != tt.n || days[0].Format("2006-01-02") != tt.first || days[len(days)-1].Format("2006-01-02") != tt.last {
+			t.Errorf("%s: got %d days from %s to %s", tt.name, len(days), days[0].Format("2006-01-02"), days[len(days)-1].Format("2006-01-02"))
+		}
+	}
+
+	// Months are parsed in UTC and weeks in local time, as before timesheets.
+	if days, _ := periodDays(CategoryMonthly, "2025-01"); days[0].Location() != time.UTC {
+		t.Errorf("month starts in %v, want UTC", days[0].Location())
+	}
+	if days, _ := periodDays(CategoryWeekly, "2025-W03"); days[0].Location() != time.Local {
+		t.Errorf("week starts in %v, want Local", days[0].Location())
+	}
+
+	for _, bad := range []string{"2025", "January", "2025-13"} {
+		if _, err := ParsePeriod(bad); err == nil {
+			t.Errorf("ParsePeriod(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestGatherReferenceContentUnpaddedWeek(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "shipped it\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, week := range []string{"2025-W03", "2025-W3"} {
+		ref, err := s.GatherReferenceContent("alpha", CategoryWeekly, week)
+		if err != nil || !strings.Contains(ref, "shipped it") {
+			t.Errorf("%s: got %q, %v", week, ref, err)
+		}
+	}
+}
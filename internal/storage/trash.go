@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrashKind says what a trashed item was.
+type TrashKind string
+
+const (
+	TrashProject TrashKind = "project"
+	TrashNote    TrashKind = "note"
+)
+
+// trashIDFormat names trash entries; it sorts chronologically.
+const trashIDFormat = "20060102T150405.000000000"
+
+// TrashItem describes something that was deleted and can be restored.
+// Each item lives in .trash/<ID>/, holding meta.json and the item itself.
+type TrashItem struct {
+	ID        string    `json:"id"`
+	Kind      TrashKind `json:"kind"`
+	Project   string    `json:"project"`
+	Category  Category  `json:"category,omitempty"` // notes only
+	Name      string    `json:"name,omitempty"`     // notes only
+	Path      string    `json:"path"`               // original location, relative to the root
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Label returns a short description, e.g. "alpha/days/2025-01-15".
+func (t TrashItem) Label() string {
+	if t.Kind == TrashProject {
+		return t.Project
+	}
+	return docKey(t.Project, t.Category, t.Name)
+}
+
+func (s *Store) trashDir() string {
+	return filepath.Join(s.Root, ".trash")
+}
+
+// moveToTrash moves the file or directory at item.Path into a new trash
+// entry. The caller must hold the store lock.
+func (s *Store) moveToTrash(item TrashItem) error {
+	item.ID = time.Now().Format(trashIDFormat)
+	item.DeletedAt = time.Now()
+
+	dir := filepath.Join(s.trashDir(), item.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create trash entry: %w", err)
+	}
+	meta, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode trash metadata: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, "meta.json"), meta, 0644); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("could not write trash metadata: %w", err)
+	}
+	if err := os.Rename(filepath.Join(s.Root, item.Path), filepath.Join(dir, "item")); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("could not move %s to trash: %w", item.Label(), err)
+	}
+	return nil
+}
+
+// ListTrash returns everything in the trash, most recently deleted first.
+func (s *Store) ListTrash() ([]TrashItem, error) {
+	entries, err := os.ReadDir(s.trashDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read trash: %w", err)
+	}
+
+	var items []TrashItem
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		item, err := s.readTrashItem(e.Name())
+		if err != nil {
+			continue // half-written entry; skip rather than fail the listing
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID > items[j].ID
+	})
+	return items, nil
+}
+
+func (s *Store) readTrashItem(id string) (TrashItem, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return TrashItem{}, fmt.Errorf("invalid trash id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(s.trashDir(), id, "meta.json"))
+	if err != nil {
+		return TrashItem{}, fmt.Errorf("trash item %q not found", id)
+	}
+	var item TrashItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return TrashItem{}, fmt.Errorf("could not parse trash metadata for %q: %w", id, err)
+	}
+	item.ID = id
+	return item, nil
+}
+
+// RestoreTrash moves a trashed project or note back to where it came from.
+// It fails if something else has since been created at that location.
+func (s *Store) RestoreTrash(id string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	item, err := s.readTrashItem(id)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(s.Root, item.Path)
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("cannot restore %s: it already exists", item.Label())
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("could not recreate directory: %w", err)
+	}
+	entry := filepath.Join(s.trashDir(), id)
+	if err := os.Rename(filepath.Join(entry, "item"), dest); err != nil {
+		return fmt.Errorf("could not restore %s: %w", item.Label(), err)
+	}
+	if err := os.RemoveAll(entry); err != nil {
+		return fmt.Errorf("could not remove trash entry: %w", err)
+	}
+
+	switch item.Kind {
+	case TrashProject:
+		s.indexProject(item.Project)
+		s.recordProjectChange(item.Project, "restore project")
+	case TrashNote:
+		s.indexNote(item.Project, item.Category, item.Name)
+		s.recordChange(item.Project, item.Category, item.Name, false)
+	}
+	return nil
+}
+
+// PurgeTrashItem permanently deletes one item from the trash.
+func (s *Store) PurgeTrashItem(id string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := s.readTrashItem(id); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(s.trashDir(), id)); err != nil {
+		return fmt.Errorf("could not purge trash item: %w", err)
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes items that have been in the trash for
+// longer than olderThan and returns how many were removed.
+func (s *Store) PurgeTrash(olderThan time.Duration) (int, error) {
+	items, err := s.ListTrash()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+	for _, item := range items {
+		if item.DeletedAt.After(cutoff) {
+			continue
+		}
+		if err := s.PurgeTrashItem(item.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// NoteVersion identifies the state of a note file on disk at the time it
+// was read, so a later write can tell whether someone else changed it.
+type NoteVersion struct {
+	Exists  bool
+	ModTime time.Time
+	Size    int64
+	Hash    string // hex SHA-256 of the content
+}
+
+// ConflictError is returned by WriteNote when the note was
+// modified on disk after it was read.
+type ConflictError struct {
+	Project  string
+	Category Category
+	Name     string
+	Disk     string      // content currently on disk
+	Version  NoteVersion // version of the content currently on disk
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("note %s/%s/%s was modified outside teatime", e.Project, e.Category, e.Name)
+}
+
+// ReadNoteVersion reads a note like ReadNote and also returns its version.
+// A note that does not exist yields empty content and a zero version.
+func (s *Store) ReadNoteVersion(project string, category Category, name string) (string, NoteVersion, error) {
+	content, version, err := readVersioned(s.notePath(project, category, name))
+	if err != nil {
+		return "", NoteVersion{}, fmt.Errorf("could not read note: %w", err)
+	}
+	return content, version, nil
+}
+
+// sameContent reports whether two versions describe the same file content.
+// Matching mtime and size are taken as unchanged without comparing hashes;
+// a touched but identical file is not a conflict either.
+func (v NoteVersion) sameContent(other NoteVersion) bool {
+	if v.Exists != other.Exists {
+		return false
+	}
+	if !v.Exists {
+		return true
+	}
+	if v.ModTime.Equal(other.ModTime) && v.Size == other.Size {
+		return true
+	}
+	return v.Hash == other.Hash
+}
+
+func readVersioned(path string) (string, NoteVersion, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", NoteVersion{}, nil
+	}
+	if err != nil {
+		return "", NoteVersion{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", NoteVersion{}, err
+	}
+	data := make([]byte, info.Size())
+	if _, err := f.ReadAt(data, 0); err != nil && info.Size() > 0 {
+		return "", NoteVersion{}, err
+	}
+	sum := sha256.Sum256(data)
+	return string(data), NoteVersion{
+		Exists:  true,
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Hash:    hex.EncodeToString(sum[:]),
+	}, nil
+}
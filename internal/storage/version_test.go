@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWriteNoteRefusesStaleWrite(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "opened\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	_, base, err := s.ReadNoteVersion("alpha", CategoryDaily, "2025-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Someone else changes the note, as vim would, after it was read.
+	path := s.notePath("alpha", CategoryDaily, "2025-01-15")
+	if err := os.WriteFile(path, []byte("changed in vim\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := base.ModTime.Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.WriteNote("alpha", CategoryDaily, "2025-01-15", "mine\n", base)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got %v, want a *ConflictError", err)
+	}
+	if conflict.Disk != "changed in vim\n" {
+		t.Errorf("conflict disk content = %q", conflict.Disk)
+	}
+	if got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-15"); got != "changed in vim\n" {
+		t.Errorf("stale write went through: %q", got)
+	}
+
+	// Saving against the version in the conflict overwrites it.
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "mine\n", conflict.Version); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ReadNote("alpha", CategoryDaily, "2025-01-15"); got != "mine\n" {
+		t.Errorf("got %q after overwriting", got)
+	}
+}
+
+func TestWriteNoteRefusesToReplaceNoteExpectedNew(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "first\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "second\n", NoteVersion{})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got %v, want a *ConflictError", err)
+	}
+}
+
+func TestWriteNoteAcceptsTouchedButIdenticalNote(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "same\n", NoteVersion{}); err != nil {
+		t.Fatal(err)
+	}
+	_, base, err := s.ReadNoteVersion("alpha", CategoryDaily, "2025-01-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := base.ModTime.Add(time.Second)
+	if err := os.Chtimes(s.notePath("alpha", CategoryDaily, "2025-01-15"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteNote("alpha", CategoryDaily, "2025-01-15", "mine\n", base); err != nil {
+		t.Errorf("touched note treated as a conflict: %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gabrielfornes/teatime/internal/ask"
+	"github.com/gabrielfornes/teatime/internal/llm"
+)
+
+// --- Screen: Ask ---
+
+func (m Model) enterAsk() (tea.Model, tea.Cmd) {
+	m.askReturn = m.screen
+	m.screen = screenAsk
+	m.statusMsg = ""
+	m.askFocusSources = false
+	m.askInput.Focus()
+	m.askViewport = viewport.New(m.askViewportSize())
+	m.askViewport.SetContent(m.askAnswerView())
+	return m, m.askInput.Cursor.BlinkCmd()
+}
+
+// askViewportSize returns the width and height of the answer, leaving
+// room for the list of sources below it.
+func (m Model) askViewportSize() (int, int) {
+	return max(m.width-6, 20), max(m.height-12-len(m.askSources), 3)
+}
+
+func (m Model) updateAsk(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "ctrl+c":
+			m = m.stopAsk()
+			return m, tea.Quit
+		case "esc":
+			if m.asking {
+				m = m.stopAsk()
+				m.statusMsg = "Stopped"
+				m.statusErr = false
+				return m, nil
+			}
+			m.screen = m.askReturn
+			m.askInput.Blur()
+			return m, nil
+		case "tab":
+			if len(m.askSources) > 0 {
+				m.askFocusSources = !m.askFocusSources
+				if m.askFocusSources {
+					m.askInput.Blur()
+				} else {
+					m.askInput.Focus()
+				}
+			}
+			return m, nil
+		case "pgup", "pgdown":
+			var cmd tea.Cmd
+			m.askViewport, cmd = m.askViewport.Update(msg)
+			return m, cmd
+		}
+
+		if m.askFocusSources {
+			switch key.String() {
+			case "up", "k":
+				if m.askCursor > 0 {
+					m.askCursor--
+				}
+			case "down", "j":
+				if m.askCursor < len(m.askSources)-1 {
+					m.askCursor++
+				}
+			case "enter":
+				src := m.askSources[m.askCursor]
+				m = m.stopAsk()
+				m.currentProject = src.Project
+				m.reminders = nil
+				m.menuCursor = 0
+				next, cmd := m.enterEditMode(src.Category, src.Name)
+				// The project may have changed, so refresh what the project view shows
+				// for when the editor is closed.
+				return next, tea.Batch(cmd, next.(Model).loadTodayNote(), next.(Model).loadReminders(), next.(Model).loadTimer())
+			}
+			return m, nil
+		}
+
+		if key.String() == "enter" {
+			return m.startAsk()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.askInput, cmd = m.askInput.Update(msg)
+	return m, cmd
+}
+
+// startAsk sends the question in the input to the model.
+func (m Model) startAsk() (tea.Model, tea.Cmd) {
+	question := strings.TrimSpace(m.askInput.Value())
+	if question == "" {
+		return m, nil
+	}
+	if m.llm == nil {
+		m.statusMsg = m.llmErr.Error()
+		m.statusErr = true
+		return m, nil
+	}
+	m = m.stopAsk()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.askCancel = cancel
+	m.asking = true
+	m.askSeq++
+	m.askAnswer = ""
+	m.askSources = nil
+	m.askCursor = 0
+	m.statusMsg = "Searching your notes…"
+	m.statusErr = false
+	m.askViewport.Width, m.askViewport.Height = m.askViewportSize()
+	m.askViewport.SetContent("")
+	return m, m.requestAnswer(ctx, m.askSeq, question)
+}
+
+// stopAsk cancels a running answer, keeping what has arrived so far.
+func (m Model) stopAsk() Model {
+	if m.askCancel != nil {
+		m.askCancel()
+	}
+	m.askCancel = nil
+	m.askStream = nil
+	m.asking = false
+	return m
+}
+
+// askAnswerView returns the answer wrapped to the viewport, or a hint
+// before the first question.
+func (m Model) askAnswerView() string {
+	if m.askAnswer == "" && !m.asking {
+		return mutedStyle.Render("Ask a question about your notes, e.g. “when did we migrate the billing DB?”")
+	}
+	w, _ := m.askViewportSize()
+	return lipgloss.NewStyle().Width(w).Render(m.askAnswer)
+}
+
+func (m Model) viewAsk() string {
+	title := titleStyle.Render("🍵 teatime — ask")
+
+	input := "? " + m.askInput.View()
+
+	var sources string
+	if len(m.askSources) > 0 {
+		lines := []string{paneHeaderStyle.Render("Sources")}
+		for i, src := range m.askSources {
+			line := searchLocationStyle.Render(src.Source())
+			if m.askFocusSources && i == m.askCursor {
+				lines = append(lines, selectedItemStyle.Render("  > ")+line)
+			} else {
+				lines = append(lines, "    "+line)
+			}
+		}
+		sources = strings.Join(lines, "\n")
+	}
+
+	status := ""
+	if m.statusMsg != "" {
+		if m.statusErr {
+			status = errorStyle.Render(m.statusMsg)
+		} else {
+			status = successStyle.Render(m.statusMsg)
+		}
+	}
+
+	var help string
+	switch {
+	case m.asking:
+		help = helpEntry("esc", "stop")
+	case m.askFocusSources:
+		help = helpEntry("↑/↓", "navigate") + "  " +
+			helpEntry("enter", "open") + "  " +
+			helpEntry("tab", "question") + "  " +
+			helpEntry("esc", "back")
+	default:
+		help = helpEntry("enter", "ask") + "  " +
+			helpEntry("pgup/pgdn", "scroll")
+		if len(m.askSources) > 0 {
+			help += "  " + helpEntry("tab", "sources")
+		}
+		help += "  " + helpEntry("esc", "back")
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, input, "", m.askViewport.View(), "", sources, status, helpBarStyle.Render(help))
+}
+
+// --- Ask commands ---
+
+type askStartedMsg struct {
+	seq      int
+	passages []ask.Passage
+	stream   <-chan llm.Delta
+	err      error
+}
+
+// requestAnswer retrieves the passages relevant to question and starts
+// streaming the model's answer.
+func (m Model) requestAnswer(ctx context.Context, seq int, question string) tea.Cmd {
+	provider, retriever := m.llm, m.retriever
+	return func() tea.Msg {
+		passages, err := retriever.Retrieve(ctx, question, ask.DefaultPassages)
+		if err != nil || len(passages) == 0 {
+			return askStartedMsg{seq: seq, err: err}
+		}
+		stream, err := provider.Stream(ctx, ask.Messages(question, passages))
+		return askStartedMsg{seq: seq, passages: passages, stream: stream, err: err}
+	}
+}
+
+// updateAskMsg handles the ask screen's async results.
+func (m Model) updateAskMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case askStartedMsg:
+		if msg.seq != m.askSeq || !m.asking {
+			return m, nil // stopped, or a newer question was asked
+		}
+		if msg.err != nil {
+			m = m.stopAsk()
+			m.statusMsg = "Error: " + msg.err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		if msg.stream == nil {
+			m = m.stopAsk()
+			m.statusMsg = "No notes look relevant to that question."
+			m.statusErr = false
+			return m, nil
+		}
+		m.askStream = msg.stream
+		m.askSources = ask.Sources(msg.passages)
+		m.askViewport.Width, m.askViewport.Height = m.askViewportSize()
+		m.statusMsg = "Answering…"
+		return m, waitForDelta(msg.stream)
+
+	case streamDeltaMsg:
+		m.askAnswer += msg.text
+		m.askViewport.SetContent(m.askAnswerView())
+		m.askViewport.GotoBottom()
+		return m, waitForDelta(msg.stream)
+
+	case streamDoneMsg:
+		m = m.stopAsk()
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+			m.statusErr = true
+		} else {
+			m.statusMsg = ""
+		}
+		m.askViewport.SetContent(m.askAnswerView())
+	}
+	return m, nil
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// autosaveInterval is how often unsaved edits are written to the note's
+// draft file under .drafts/.
+const autosaveInterval = 5 * time.Second
+
+// --- Autosave commands ---
+//
+// While a note is being edited, unsaved changes are autosaved every
+// autosaveInterval. The draft is deleted once the note is saved or the
+// changes are discarded, so a draft left behind means a session ended
+// without either, and enterEditMode offers to recover it. A draft that
+// matches the note as loaded recovers nothing and is deleted instead.
+
+type autosaveTickMsg struct {
+	session int // the edit session that scheduled the tick
+}
+
+type autosavedMsg struct {
+	err error
+}
+
+// autosaveTick schedules the next autosave of an edit session.
+func autosaveTick(session int) tea.Cmd {
+	return tea.Tick(autosaveInterval, func(time.Time) tea.Msg {
+		return autosaveTickMsg{session: session}
+	})
+}
+
+func (m Model) saveAutosave(project string, category storage.Category, name, content string) tea.Cmd {
+	return func() tea.Msg {
+		return autosavedMsg{err: m.store.SaveDraft(project, category, name, content)}
+	}
+}
+
+// deleteAutosave removes a note's draft. Failing is harmless: the draft is
+// offered for recovery next time, and can be discarded then.
+func (m Model) deleteAutosave(project string, category storage.Category, name string) tea.Cmd {
+	return func() tea.Msg {
+		_ = m.store.DeleteDraft(project, category, name)
+		return nil
+	}
+}
+
+// updateAutosaveMsg handles autosave ticks and results.
+func (m Model) updateAutosaveMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case autosaveTickMsg:
+		if msg.session != m.editSession || m.screen != screenEdit {
+			return m, nil // the editor was closed; its ticks stop here
+		}
+		var save tea.Cmd
+		// Nothing is autosaved while a recovered draft awaits a decision, so
+		// it can't be overwritten by the note it would replace.
+		if value := m.editTextarea.Value(); m.editDirty && m.editRecovery == nil && value != m.editAutosaved {
+			m.editAutosaved = value
+			save = m.saveAutosave(m.currentProject, m.editCategory, m.editNoteName, value)
+		}
+		return m, tea.Batch(save, autosaveTick(m.editSession))
+
+	case autosavedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Autosave failed: " + msg.err.Error()
+			m.statusErr = true
+		}
+	}
+	return m, nil
+}
+
+// updateEditRecovery handles the prompt shown when a note has a draft left
+// over from an edit session that ended without saving.
+func (m Model) updateEditRecovery(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	draft := m.editRecovery
+
+	switch key.String() {
+	case "r":
+		m.editTextarea.SetValue(draft.Content)
+		m.editAutosaved = draft.Content
+		m.editRecovery = nil
+		m.editDirty = true
+		m.statusMsg = "Recovered unsaved changes — review, then esc to save"
+		m.statusErr = false
+		return m, nil
+	case "d":
+		m.editRecovery = nil
+		m.statusMsg = "Unsaved changes discarded"
+		m.statusErr = false
+		return m, m.deleteAutosave(m.currentProject, m.editCategory, m.editNoteName)
+	case "ctrl+c":
+		// Leave without deciding; the draft is offered again next time.
+		m.editRecovery = nil
+		m.screen = screenProjectView
+		m.statusMsg = "Edit cancelled"
+		m.statusErr = false
+		return m, nil
+	}
+	return m, nil
+}
+
+// updateEditConfirmDiscard handles the y/n prompt shown when ctrl+c would
+// discard unsaved changes.
+func (m Model) updateEditConfirmDiscard(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "y", "Y":
+		m.editConfirmDiscard = false
+		m.editConflict = nil
+		m.editDirty = false
+		m.screen = screenProjectView
+		m.statusMsg = "Edit cancelled"
+		m.statusErr = false
+		return m, m.deleteAutosave(m.currentProject, m.editCategory, m.editNoteName)
+	case "n", "N", "esc":
+		m.editConfirmDiscard = false
+		m.statusMsg = ""
+		return m, nil
+	}
+	return m, nil
+}
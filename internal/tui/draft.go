@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gabrielfornes/teatime/internal/llm"
+	"github.com/gabrielfornes/teatime/internal/redact"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// --- LLM stream commands ---
+//
+// Replies stream in one streamDeltaMsg at a time. The stream travels with
+// each message so that pieces of a stopped reply can be told apart from
+// the current one.
+
+type streamDeltaMsg struct {
+	stream <-chan llm.Delta
+	text   string
+}
+
+type streamDoneMsg struct {
+	stream <-chan llm.Delta
+	err    error
+}
+
+// waitForDelta reads the next piece of a reply.
+func waitForDelta(stream <-chan llm.Delta) tea.Cmd {
+	return func() tea.Msg {
+		d, ok := <-stream
+		if !ok {
+			return streamDoneMsg{stream: stream}
+		}
+		if d.Err != nil {
+			return streamDoneMsg{stream: stream, err: d.Err}
+		}
+		return streamDeltaMsg{stream: stream, text: d.Text}
+	}
+}
+
+// updateStreamMsg hands a piece of a reply to the screen that asked for it.
+func (m Model) updateStreamMsg(msg tea.Msg) (Model, tea.Cmd) {
+	var stream <-chan llm.Delta
+	switch msg := msg.(type) {
+	case streamDeltaMsg:
+		stream = msg.stream
+	case streamDoneMsg:
+		stream = msg.stream
+	}
+	switch stream {
+	case m.draftStream:
+		return m.updateDraftMsg(msg)
+	case m.askStream:
+		return m.updateAskMsg(msg)
+	}
+	return m, nil // from a reply that was stopped
+}
+
+// --- LLM draft commands ---
+//
+// ctrl+g in the split-pane editor sends the reference content to the
+// configured model and streams its summary into the textarea.
+
+type draftStartedMsg struct {
+	stream <-chan llm.Delta
+	err    error
+}
+
+// startDraft begins drafting the note being edited. The textarea is
+// locked until the stream ends or esc stops it.
+func (m Model) startDraft() (Model, tea.Cmd) {
+	if m.llm == nil {
+		m.statusMsg = m.llmErr.Error()
+		m.statusErr = true
+		return m, nil
+	}
+	messages, err := m.draftRequest()
+	if err != nil {
+		m.statusMsg = "Error drafting: " + err.Error()
+		m.statusErr = true
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.editFocusLeft = true
+	m.editTextarea.Focus()
+	m.draftCancel = cancel
+	m.drafting = true
+	m.statusMsg = "Drafting…"
+	m.statusErr = false
+	return m, m.requestDraft(ctx, messages)
+}
+
+// stopDraft cancels a running draft, keeping what has arrived so far.
+func (m Model) stopDraft() Model {
+	if m.draftCancel != nil {
+		m.draftCancel()
+	}
+	m.draftCancel = nil
+	m.draftStream = nil
+	m.drafting = false
+	return m
+}
+
+func (m Model) requestDraft(ctx context.Context, messages []llm.Message) tea.Cmd {
+	provider := m.llm
+	return func() tea.Msg {
+		stream, err := provider.Stream(ctx, messages)
+		return draftStartedMsg{stream: stream, err: err}
+	}
+}
+
+// draftRequest builds the chat that ctrl+g sends for the note being
+// edited, from the reference content shown in the right pane. The
+// provider redacts it on the way out, with the same redactor the preview
+// uses.
+func (m Model) draftRequest() ([]llm.Message, error) {
+	switch {
+	case m.editRefErr != nil:
+		return nil, m.editRefErr
+	case m.editRef == "":
+		return nil, errors.New("the reference content is still loading")
+	case strings.HasPrefix(m.editRef, "(no "):
+		// The placeholder GatherReferenceContent returns for an empty period.
+		return nil, errors.New("nothing to summarize yet: " + m.editRef)
+	}
+	return draftMessages(m.draftPrompt(), m.editCategory, m.editNoteName, m.editRef), nil
+}
+
+// draftPrompt returns the instructions for drafting the note being edited:
+// its prompt template if there is one, and otherwise the configured prompt.
+func (m Model) draftPrompt() string {
+	if m.editPrompt != "" {
+		return m.editPrompt
+	}
+	return m.llmPrompt
+}
+
+// draftMessages builds the chat that asks for a draft of the named note.
+func draftMessages(prompt string, category storage.Category, name, reference string) []llm.Message {
+	period := storage.CategoryLabel(category) + " " + name
+	return llm.SummaryMessages(prompt, period, reference)
+}
+
+// draftPreview renders exactly what ctrl+g sends for the note being
+// edited, after redaction, with the redacted spans highlighted.
+func (m Model) draftPreview() string {
+	width := max(m.editViewport.Width, 20)
+	messages, err := m.draftRequest()
+	if err != nil {
+		return mutedStyle.Render("Nothing would be sent: " + err.Error())
+	}
+	var all []redact.Redaction
+	var b strings.Builder
+	for _, msg := range messages {
+		content, redactions := m.redactor.Redact(msg.Content)
+		all = append(all, redactions...)
+		var hl strings.Builder
+		pos := 0
+		for _, r := range redactions {
+			hl.WriteString(content[pos:r.Start])
+			hl.WriteString(matchStyle.Render(content[r.Start:r.End]))
+			pos = r.End
+		}
+		hl.WriteString(content[pos:])
+		b.WriteString("\n" + paneHeaderStyle.Render(msg.Role) + "\n")
+		b.WriteString(lipgloss.NewStyle().Width(width).Render(strings.TrimRight(hl.String(), "\n")) + "\n")
+	}
+	return mutedStyle.Render(redact.Describe(all)) + "\n" + b.String()
+}
+
+// updateDraftMsg handles the draft's async results.
+func (m Model) updateDraftMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case draftStartedMsg:
+		if !m.drafting {
+			return m, nil // stopped before the model answered
+		}
+		if msg.err != nil {
+			m = m.stopDraft()
+			m.statusMsg = "Error drafting: " + msg.err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		m.draftStream = msg.stream
+		m.editDirty = true
+		if value := m.editTextarea.Value(); strings.TrimSpace(value) != "" {
+			// Append below what is already written; SetValue leaves the cursor at the end.
+			m.editTextarea.SetValue(strings.TrimRight(value, "\n") + "\n\n")
+		}
+		return m, waitForDelta(msg.stream)
+
+	case streamDeltaMsg:
+		m.editTextarea.InsertString(msg.text)
+		return m, waitForDelta(msg.stream)
+
+	case streamDoneMsg:
+		m = m.stopDraft()
+		if msg.err != nil {
+			m.statusMsg = "Error drafting: " + msg.err.Error()
+			m.statusErr = true
+		} else {
+			m.statusMsg = "Draft ready — review, then esc to save"
+			m.statusErr = false
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// --- External editor commands ---
+//
+// E suspends the TUI and opens a note in $VISUAL or $EDITOR. Summaries
+// also get their reference content as a second, read-only file.
+
+type externalEditReadyMsg struct {
+	project  string
+	category storage.Category
+	name     string
+	before   string // the note's content when the editor opened
+	file     string // what the editor opens: the note, or a copy of its template
+	template string // for a new note, the template in file
+	sidecar  string // reference content file, "" for daily notes
+	err      error
+}
+
+type externalEditDoneMsg struct {
+	ready externalEditReadyMsg
+	err   error // from running the editor
+}
+
+type externalEditSavedMsg struct {
+	changed bool
+	err     error
+}
+
+// editorCommand returns the command that opens files in the user's editor:
+// $VISUAL, then $EDITOR, then vi. The variable may carry arguments, as in
+// "code --wait".
+func editorCommand(files ...string) (*exec.Cmd, error) {
+	editor := os.Getenv("VISUAL")
+	if strings.TrimSpace(editor) == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not find editor %q: set $EDITOR", args[0])
+	}
+	return exec.Command(path, append(args[1:], files...)...), nil
+}
+
+// openInEditor gets a note ready for $EDITOR. A new note with a template
+// is edited as a temporary copy of the template, so that nothing is
+// created unless the editor changes it. A summary's reference content is
+// written to a sidecar file.
+func (m Model) openInEditor(category storage.Category, name string) tea.Cmd {
+	project := m.currentProject
+	return func() tea.Msg {
+		msg := externalEditReadyMsg{project: project, category: category, name: name}
+		msg.file = m.store.NotePath(project, category, name)
+		if !m.store.NoteExists(project, category, name) {
+			msg.template, msg.err = m.store.NoteTemplate(project, category, name)
+			if msg.err == nil && msg.template != "" {
+				msg.file, msg.err = writeTempNote(project+"-"+name, msg.template, 0644)
+			}
+		} else {
+			msg.before, msg.err = m.store.ReadNote(project, category, name)
+		}
+		if msg.err != nil || category == storage.CategoryDaily {
+			return msg
+		}
+		ref, err := m.store.GatherReferenceContent(project, category, name)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		header := "<!-- " + referenceLabel(category) + " for " + name + " (read-only; not saved) -->\n\n"
+		msg.sidecar, msg.err = writeTempNote(project+"-"+name+"-reference", header+ref, 0444)
+		return msg
+	}
+}
+
+// writeTempNote writes content to a new temporary markdown file whose name
+// starts with "teatime-"+label, and returns its path.
+func writeTempNote(label, content string, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp("", "teatime-"+label+"-*.md")
+	if err != nil {
+		return "", fmt.Errorf("could not create temporary file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("could not write temporary file: %w", err)
+	}
+	_ = f.Chmod(perm)
+	return f.Name(), nil
+}
+
+// finishExternalEdit records what the editor did to the note. A template
+// copy is saved as the note only if it was changed.
+func (m Model) finishExternalEdit(ready externalEditReadyMsg) (bool, error) {
+	if ready.template == "" {
+		return m.store.NoteEdited(ready.project, ready.category, ready.name, ready.before)
+	}
+	data, err := os.ReadFile(ready.file)
+	if err != nil {
+		return false, fmt.Errorf("could not read edited note: %w", err)
+	}
+	content := string(data)
+	changed := content != ready.template && strings.TrimSpace(content) != ""
+	if changed {
+		// The note must still not exist: one created meanwhile is a conflict.
+		if err := m.store.WriteNote(ready.project, ready.category, ready.name, content, storage.NoteVersion{}); err != nil {
+			// Keep the copy so the edits aren't lost.
+			return false, fmt.Errorf("%w (your edits are in %s)", err, ready.file)
+		}
+	}
+	os.Remove(ready.file)
+	return changed, nil
+}
+
+// updateExternalEditMsg handles the editor's lifecycle: start it once the
+// note is ready, then record what changed when it exits.
+func (m Model) updateExternalEditMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case externalEditReadyMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		files := []string{msg.file}
+		if msg.sidecar != "" {
+			files = append(files, msg.sidecar)
+		}
+		cmd, err := editorCommand(files...)
+		if err != nil {
+			if msg.template != "" {
+				os.Remove(msg.file)
+			}
+			if msg.sidecar != "" {
+				os.Remove(msg.sidecar)
+			}
+			m.statusMsg = err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return externalEditDoneMsg{ready: msg, err: err}
+		})
+
+	case externalEditDoneMsg:
+		ready := msg.ready
+		if ready.sidecar != "" {
+			os.Remove(ready.sidecar)
+		}
+		// Whatever the editor's exit status, it may have written the file.
+		return m, func() tea.Msg {
+			changed, err := m.finishExternalEdit(ready)
+			if err == nil && msg.err != nil {
+				err = fmt.Errorf("editor failed: %w", msg.err)
+			}
+			return externalEditSavedMsg{changed: changed, err: err}
+		}
+
+	case externalEditSavedMsg:
+		switch {
+		case msg.err != nil:
+			m.statusMsg = "Error: " + msg.err.Error()
+			m.statusErr = true
+		case msg.changed:
+			m.statusMsg = "Saved ✓"
+			m.statusErr = false
+		default:
+			m.statusMsg = "No changes"
+			m.statusErr = false
+		}
+		cmds := []tea.Cmd{m.loadTodayNote(), m.loadReminders(), m.loadTimer()}
+		if m.screen == screenNoteList && len(m.notes) > 0 {
+			cmds = append(cmds, m.loadNoteContent(m.currentProject, m.noteCategory, m.notes[m.noteCursor].Name, "preview"))
+		}
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
@@ -0,0 +1,283 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// diffContextLines is how many unchanged lines are shown around each change.
+const diffContextLines = 2
+
+// --- Screen: History ---
+
+func (m Model) enterHistory() (tea.Model, tea.Cmd) {
+	if len(m.notes) == 0 {
+		return m, nil
+	}
+	m.screen = screenHistory
+	m.historyNote = m.notes[m.noteCursor].Name
+	m.historyRevisions = nil
+	m.historyCursor = 0
+	m.historyCurrent = ""
+	m.historyVersion = storage.NoteVersion{}
+	m.statusMsg = ""
+
+	_, rw, ph := m.projectViewLayout()
+	m.historyViewport = viewport.New(max(rw-4, 20), max(ph-4, 3))
+	return m, m.loadRevisions(m.currentProject, m.noteCategory, m.historyNote)
+}
+
+func (m Model) updateHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "b", "esc":
+			m.screen = screenNoteList
+			m.statusMsg = ""
+			// The note may have been restored; refresh its preview.
+			return m, m.loadNoteContent(m.currentProject, m.noteCategory, m.historyNote, "preview")
+		case "up", "k":
+			if m.historyCursor > 0 {
+				m.historyCursor--
+				return m, m.loadRevisionDiff()
+			}
+		case "down", "j":
+			if m.historyCursor < len(m.historyRevisions)-1 {
+				m.historyCursor++
+				return m, m.loadRevisionDiff()
+			}
+		case "r":
+			if len(m.historyRevisions) > 0 {
+				rev := m.historyRevisions[m.historyCursor]
+				return m, m.restoreRevision(m.currentProject, m.noteCategory, m.historyNote, rev, m.historyVersion)
+			}
+		default:
+			// pgup/pgdn etc. scroll the diff
+			var cmd tea.Cmd
+			m.historyViewport, cmd = m.historyViewport.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m Model) viewHistory() string {
+	leftWidth, rightWidth, paneHeight := m.projectViewLayout()
+
+	leftContent := headerStyle.Render("History: "+m.historyNote) + "\n\n"
+	if len(m.historyRevisions) == 0 {
+		leftContent += mutedStyle.Render("No earlier revisions.\nA revision is kept each\ntime the note is overwritten.")
+	} else {
+		for i, rev := range m.historyRevisions {
+			line := rev.Time.Format("2006-01-02 15:04:05")
+			if i == m.historyCursor {
+				leftContent += selectedItemStyle.Render("  > "+line) + "\n"
+			} else {
+				leftContent += normalItemStyle.Render("    "+line) + "\n"
+			}
+		}
+	}
+
+	leftPane := leftPaneStyle.
+		Width(leftWidth).
+		Height(paneHeight).
+		Render(leftContent)
+
+	rightContent := previewHeaderStyle.Render("± Changes since this revision") + "\n"
+	if len(m.historyRevisions) > 0 {
+		rightContent += m.historyViewport.View()
+	}
+	rightPane := rightPaneStyle.
+		Width(rightWidth).
+		Height(paneHeight).
+		Render(rightContent)
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
+	title := titleStyle.Render("🍵 teatime — " + m.projectTitle(m.currentProject))
+
+	status := ""
+	if m.statusMsg != "" {
+		if m.statusErr {
+			status = errorStyle.Render(m.statusMsg)
+		} else {
+			status = successStyle.Render(m.statusMsg)
+		}
+	}
+
+	help := helpBarStyle.Render(
+		helpEntry("↑/↓", "navigate") + "  " +
+			helpEntry("pgup/pgdn", "scroll diff") + "  " +
+			helpEntry("r", "restore") + "  " +
+			helpEntry("b", "back") + "  " +
+			helpEntry("q", "quit"),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, body, status, help)
+}
+
+// renderDiff styles a line diff, collapsing long runs of unchanged lines.
+func renderDiff(lines []storage.DiffLine) string {
+	changed := false
+	for _, l := range lines {
+		if l.Op != storage.DiffEqual {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return mutedStyle.Render("(identical to the current note)")
+	}
+
+	// Mark the unchanged lines that are close enough to a change to show.
+	show := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.Op == storage.DiffEqual {
+			continue
+		}
+		for j := max(i-diffContextLines, 0); j <= min(i+diffContextLines, len(lines)-1); j++ {
+			show[j] = true
+		}
+	}
+
+	var b strings.Builder
+	skipped := false
+	for i, l := range lines {
+		if !show[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			b.WriteString(mutedStyle.Render("⋯") + "\n")
+			skipped = false
+		}
+		switch l.Op {
+		case storage.DiffInsert:
+			b.WriteString(diffInsertStyle.Render("+ "+l.Text) + "\n")
+		case storage.DiffDelete:
+			b.WriteString(diffDeleteStyle.Render("- "+l.Text) + "\n")
+		default:
+			b.WriteString(mutedStyle.Render("  "+l.Text) + "\n")
+		}
+	}
+	if skipped {
+		b.WriteString(mutedStyle.Render("⋯") + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// --- History commands ---
+
+type revisionsLoadedMsg struct {
+	revisions []storage.Revision
+	current   string
+	version   storage.NoteVersion
+	err       error
+}
+
+type revisionDiffMsg struct {
+	id   string
+	diff string
+	err  error
+}
+
+type revisionRestoredMsg struct {
+	rev storage.Revision
+	err error
+}
+
+func (m Model) loadRevisions(project string, category storage.Category, name string) tea.Cmd {
+	return func() tea.Msg {
+		revs, err := m.store.ListRevisions(project, category, name)
+		if err != nil {
+			return revisionsLoadedMsg{err: err}
+		}
+		current, version, err := m.store.ReadNoteVersion(project, category, name)
+		return revisionsLoadedMsg{revisions: revs, current: current, version: version, err: err}
+	}
+}
+
+// loadRevisionDiff diffs the selected revision against the current content.
+func (m Model) loadRevisionDiff() tea.Cmd {
+	if len(m.historyRevisions) == 0 {
+		return nil
+	}
+	project, category, name := m.currentProject, m.noteCategory, m.historyNote
+	rev := m.historyRevisions[m.historyCursor]
+	current := m.historyCurrent
+	return func() tea.Msg {
+		old, err := m.store.ReadRevision(project, category, name, rev.ID)
+		if err != nil {
+			return revisionDiffMsg{id: rev.ID, err: err}
+		}
+		return revisionDiffMsg{id: rev.ID, diff: renderDiff(storage.DiffLines(old, current))}
+	}
+}
+
+func (m Model) restoreRevision(project string, category storage.Category, name string, rev storage.Revision, base storage.NoteVersion) tea.Cmd {
+	return func() tea.Msg {
+		err := m.store.RestoreRevision(project, category, name, rev.ID, base)
+		return revisionRestoredMsg{rev: rev, err: err}
+	}
+}
+
+// updateHistoryMsg handles the history screen's async results.
+func (m Model) updateHistoryMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case revisionsLoadedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error loading history: " + msg.err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		m.historyRevisions = msg.revisions
+		m.historyCurrent = msg.current
+		m.historyVersion = msg.version
+		if m.historyCursor >= len(m.historyRevisions) {
+			m.historyCursor = max(len(m.historyRevisions)-1, 0)
+		}
+		m.historyViewport.SetContent("")
+		return m, m.loadRevisionDiff()
+
+	case revisionDiffMsg:
+		if len(m.historyRevisions) == 0 || m.historyRevisions[m.historyCursor].ID != msg.id {
+			return m, nil // the cursor has moved on
+		}
+		if msg.err != nil {
+			m.historyViewport.SetContent(errorStyle.Render(msg.err.Error()))
+		} else {
+			m.historyViewport.SetContent(msg.diff)
+		}
+		m.historyViewport.GotoTop()
+		return m, nil
+
+	case revisionRestoredMsg:
+		var conflict *storage.ConflictError
+		if errors.As(msg.err, &conflict) {
+			// Show the diffs against what is on disk now before restoring.
+			m.statusMsg = "The note changed since the history was opened; diffs reloaded, press r again to restore"
+			m.statusErr = true
+			return m, m.loadRevisions(m.currentProject, m.noteCategory, m.historyNote)
+		}
+		if msg.err != nil {
+			m.statusMsg = "Error restoring: " + msg.err.Error()
+			m.statusErr = true
+			return m, nil
+		}
+		m.statusMsg = "Restored revision from " + msg.rev.Time.Format("2006-01-02 15:04:05") + " ✓"
+		m.statusErr = false
+		m.historyCursor = 0
+		return m, tea.Batch(
+			m.loadRevisions(m.currentProject, m.noteCategory, m.historyNote),
+			m.loadTodayNote(),
+			m.loadReminders(),
+		)
+	}
+	return m, nil
+}
@@ -1,10 +1,18 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gabrielfornes/teatime/internal/ask"
+	"github.com/gabrielfornes/teatime/internal/config"
+	"github.com/gabrielfornes/teatime/internal/llm"
+	"github.com/gabrielfornes/teatime/internal/redact"
 	"github.com/gabrielfornes/teatime/internal/storage"
 )
 
@@ -16,6 +24,12 @@ const (
 	screenProjectView
 	screenNoteList
 	screenEdit
+	screenSearch
+	screenHistory
+	screenTrash
+	screenTags
+	screenReport
+	screenAsk
 )
 
 // Model is the root Bubble Tea model for teatime.
@@ -35,6 +49,11 @@ type Model struct {
 	creatingNew   bool
 	newNameInput  textarea.Model
 
+	renamingProject      bool // newNameInput is renaming the selected project
+	confirmDeleteProject bool // waiting for y/n to delete the selected project
+	showArchived         bool // include archived projects in the list
+	projectMeta          map[string]storage.ProjectMeta
+
 	// Currently selected project
 	currentProject string
 
@@ -58,10 +77,85 @@ type Model struct {
 	editCategory    storage.Category
 	editNoteName    string
 	editDirty       bool
-	editRef         string         // reference content from the level below
-	editRefRendered string         // rendered version for the viewport
-	editViewport    viewport.Model // scrollable right pane for reference content
-	editFocusLeft   bool           // true = textarea focused, false = viewport focused
+	editRef         string                 // reference content from the level below
+	editRefErr      error                  // set if the reference content could not be gathered
+	editRefRendered string                 // rendered version for the viewport
+	editViewport    viewport.Model         // scrollable right pane for reference content
+	editFocusLeft   bool                   // true = textarea focused, false = viewport focused
+	editJumpLine    int                    // 1-based line to move the cursor to once loaded, 0 = none
+	editBase        string                 // content as loaded, the common ancestor for merges
+	editPrompt      string                 // the note's prompt template, rendered; "" uses llmPrompt
+	editVersion     storage.NoteVersion    // on-disk version when loaded, checked on save
+	editConflict    *storage.ConflictError // set while asking how to resolve a conflicting save
+
+	// Autosave state (see autosave.go)
+	editSession        int            // bumped for every note opened, so stale autosave ticks stop
+	editAutosaved      string         // content as of the last autosave
+	editRecovery       *storage.Draft // set while asking whether to recover unsaved changes
+	editConfirmDiscard bool           // waiting for y/n to discard unsaved changes
+
+	// LLM drafting state (ctrl+g in the split-pane editor)
+	llm         llm.Provider // nil if not configured
+	llmErr      error        // why llm is nil
+	llmPrompt   string
+	redactor    *redact.Redactor // applied to everything sent to llm
+	editPreview bool             // the right pane shows what ctrl+g would send
+	drafting    bool             // a draft is streaming into editTextarea
+	draftCancel context.CancelFunc
+	draftStream <-chan llm.Delta
+
+	// History state (revisions of the note selected in the note list)
+	historyNote      string
+	historyRevisions []storage.Revision
+	historyCursor    int
+	historyCurrent   string              // the note's current content, diffed against each revision
+	historyVersion   storage.NoteVersion // version of historyCurrent, checked on restore
+	historyViewport  viewport.Model      // scrollable diff
+
+	// Trash state
+	trashItems        []storage.TrashItem
+	trashCursor       int
+	trashConfirmPurge bool
+
+	// Timer state
+	timer   *storage.Session         // running timer on any project, nil if none
+	tracked map[string]time.Duration // time tracked on currentProject per day
+
+	// Timesheet report state
+	reportCategory storage.Category // CategoryWeekly or CategoryMonthly
+	reportPeriod   string           // e.g. "2025-W03"; empty until first opened
+	reportViewport viewport.Model
+	reportReturn   screen // screen to return to on esc
+
+	// Tags state
+	tagsList       []storage.TagCount
+	tagsCursor     int
+	tagNotes       []storage.TaggedNote // timeline of the tag under tagsCursor
+	tagNotesCursor int
+	tagsFocusNotes bool   // true = timeline focused, false = tag list focused
+	tagsReturn     screen // screen to return to on esc
+
+	// Ask state
+	askInput        textarea.Model
+	retriever       ask.Retriever
+	asking          bool // an answer is being retrieved or streamed
+	askSeq          int  // bumped per question so stale results are dropped
+	askCancel       context.CancelFunc
+	askStream       <-chan llm.Delta
+	askAnswer       string
+	askSources      []ask.Passage // notes the answer was drawn from
+	askCursor       int
+	askFocusSources bool
+	askViewport     viewport.Model // scrollable answer
+	askReturn       screen         // screen to return to on esc
+
+	// Search state
+	searchInput   textarea.Model
+	searchResults []storage.SearchHit
+	searchCursor  int
+	searchSeq     int    // bumped per query so stale results are dropped
+	searchReturn  screen // screen to return to on esc
+	searchErr     error
 
 	// Status message (shown briefly)
 	statusMsg string
@@ -86,7 +180,7 @@ var menuItems = []struct {
 }
 
 // NewModel creates and returns a new root model.
-func NewModel(store *storage.Store) Model {
+func NewModel(store *storage.Store, cfg *config.Config) Model {
 	ta := textarea.New()
 	ta.Placeholder = "Enter project name..."
 	ta.CharLimit = 64
@@ -100,6 +194,34 @@ func NewModel(store *storage.Store) Model {
 	editTa.SetWidth(60)
 	editTa.SetHeight(15)
 
+	searchTa := textarea.New()
+	searchTa.Placeholder = "Search notes..."
+	searchTa.ShowLineNumbers = false
+	searchTa.Prompt = ""
+	searchTa.SetWidth(50)
+	searchTa.SetHeight(1)
+	searchTa.KeyMap.InsertNewline.SetEnabled(false)
+
+	askTa := textarea.New()
+	askTa.Placeholder = "Ask about your notes..."
+	askTa.ShowLineNumbers = false
+	askTa.Prompt = ""
+	askTa.CharLimit = 500
+	askTa.SetWidth(60)
+	askTa.SetHeight(1)
+	askTa.KeyMap.InsertNewline.SetEnabled(false)
+
+	provider, err := llm.New(cfg.LLM)
+	redactor, rerr := redact.New(cfg.Redact)
+	if rerr != nil {
+		// Never send notes unredacted: with a broken rule, nothing is sent.
+		provider, err = nil, rerr
+		redactor, _ = redact.New(config.RedactConfig{Secrets: true})
+	}
+	if provider != nil {
+		provider = llm.WithFilter(provider, redactor.String)
+	}
+
 	return Model{
 		store:        store,
 		screen:       screenProjectList,
@@ -107,6 +229,13 @@ func NewModel(store *storage.Store) Model {
 		height:       defaultTerminalHeight,
 		newNameInput: ta,
 		editTextarea: editTa,
+		searchInput:  searchTa,
+		askInput:     askTa,
+		retriever:    ask.NewRetriever(store, cfg.LLM, redactor.String),
+		llm:          provider,
+		llmErr:       err,
+		llmPrompt:    cfg.LLM.Prompt,
+		redactor:     redactor,
 	}
 }
 
@@ -170,6 +299,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			_, rw, _ := m.projectViewLayout()
 			m.previewNoteRendered = ""
 			cmds = append(cmds, m.renderMarkdownCmd(m.previewNote, rw-4, "preview"))
+		case screenHistory:
+			_, rw, ph := m.projectViewLayout()
+			m.historyViewport.Width = max(rw-4, 20)
+			m.historyViewport.Height = max(ph-4, 3)
+		case screenReport:
+			m.reportViewport.Width, m.reportViewport.Height = m.reportViewportSize()
+		case screenAsk:
+			m.askViewport.Width, m.askViewport.Height = m.askViewportSize()
+			m.askViewport.SetContent(m.askAnswerView())
 		case screenEdit:
 			if m.editCategory != storage.CategoryDaily {
 				_, rw, _ := m.editPaneLayout()
@@ -181,9 +319,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case projectsLoadedMsg:
 		m.projects = msg.projects
+		m.projectMeta = msg.meta
 		m.err = msg.err
+		if m.projectCursor >= len(m.projects) {
+			m.projectCursor = max(len(m.projects)-1, 0)
+		}
 		return m, nil
 
+	case projectChangedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+			m.statusErr = true
+		} else {
+			m.statusMsg = msg.status
+			m.statusErr = false
+		}
+		return m, m.loadProjects
+
 	case remindersLoadedMsg:
 		m.reminders = msg.reminders
 		// Clamp cursor if reminders list shrank (e.g. after saving a summary)
@@ -194,11 +346,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case refContentLoadedMsg:
+		m.editRefErr = msg.err
 		if msg.err != nil {
 			m.editRef = "(error loading reference: " + msg.err.Error() + ")"
 		} else {
 			m.editRef = msg.content
 		}
+		m.editPrompt = msg.prompt
+		if msg.promptErr != nil {
+			m.statusMsg = "Error in prompt template: " + msg.promptErr.Error()
+			m.statusErr = true
+		}
 		_, rw, ph := m.editPaneLayout()
 		vpWidth := rw - 4
 		if vpWidth < 20 {
@@ -220,7 +378,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.previewNoteRendered = msg.content
 		case "edit":
 			m.editRefRendered = msg.content
-			m.editViewport.SetContent(m.editRefRendered)
+			if m.editPreview {
+				m.editViewport.SetContent(m.draftPreview())
+			} else {
+				m.editViewport.SetContent(m.editRefRendered)
+			}
 		}
 		return m, nil
 
@@ -242,7 +404,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.renderMarkdownCmd(m.previewNote, rw-4, "preview")
 			case "edit":
 				m.editTextarea.SetValue(msg.content)
+				m.editBase = msg.content
+				m.editVersion = msg.version
 				m.editDirty = false
+				if msg.template != "" {
+					// A new note starts from its template; editBase stays empty, as on disk.
+					m.editTextarea.SetValue(msg.template)
+				}
+				if msg.templateErr != nil {
+					m.statusMsg = "Error in note template: " + msg.templateErr.Error()
+					m.statusErr = true
+				}
+				var cmd tea.Cmd
+				if msg.draft != nil {
+					if msg.draft.Content == msg.content || msg.draft.Content == m.editTextarea.Value() {
+						// The draft holds nothing that isn't already on disk or in
+						// the editor, so it is stale rather than unsaved.
+						cmd = m.deleteAutosave(m.currentProject, m.editCategory, m.editNoteName)
+					} else {
+						m.editRecovery = msg.draft
+						m.statusMsg = "This note has unsaved changes from " + msg.draft.Saved.Format("Mon Jan 2 15:04") + "."
+						m.statusErr = true
+					}
+				}
+				if m.editJumpLine > 0 {
+					jumpToLine(&m.editTextarea, m.editJumpLine-1)
+					m.editJumpLine = 0
+				}
+				return m, cmd
 			}
 		}
 		return m, nil
@@ -266,13 +455,71 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.statusMsg = "Error saving: " + msg.err.Error()
 			m.statusErr = true
+			// The file on disk is unchanged and the text is still in the
+			// textarea, so reopen the editor rather than losing it.
+			if msg.category == m.editCategory && msg.name == m.editNoteName {
+				m.screen = screenEdit
+				m.editDirty = true
+				m.editTextarea.Focus()
+				m.editFocusLeft = true
+				var conflict *storage.ConflictError
+				if errors.As(msg.err, &conflict) {
+					m.editConflict = conflict
+					m.statusMsg = "This note was changed outside teatime since you opened it."
+				}
+				// Autosave stopped when the editor closed; start a new session.
+				m.editSession++
+				return m, autosaveTick(m.editSession)
+			}
 			return m, nil
 		}
 		m.statusMsg = "Saved ✓"
 		m.statusErr = false
 		m.editDirty = false
 		// Reload today's note and reminders so the project view shows the latest content
-		return m, tea.Batch(m.loadTodayNote(), m.loadReminders())
+		return m, tea.Batch(m.loadTodayNote(), m.loadReminders(), m.loadTimer(),
+			m.deleteAutosave(msg.project, msg.category, msg.name))
+
+	case revisionsLoadedMsg, revisionDiffMsg, revisionRestoredMsg:
+		return m.updateHistoryMsg(msg)
+
+	case trashLoadedMsg, trashChangedMsg:
+		return m.updateTrashMsg(msg)
+
+	case tagsLoadedMsg, tagNotesLoadedMsg:
+		return m.updateTagsMsg(msg)
+
+	case timerLoadedMsg, timerToggledMsg:
+		return m.updateTimerMsg(msg)
+
+	case timesheetLoadedMsg:
+		return m.updateReportMsg(msg)
+
+	case autosaveTickMsg, autosavedMsg:
+		return m.updateAutosaveMsg(msg)
+
+	case externalEditReadyMsg, externalEditDoneMsg, externalEditSavedMsg:
+		return m.updateExternalEditMsg(msg)
+
+	case draftStartedMsg:
+		return m.updateDraftMsg(msg)
+
+	case askStartedMsg:
+		return m.updateAskMsg(msg)
+
+	case streamDeltaMsg, streamDoneMsg:
+		return m.updateStreamMsg(msg)
+
+	case searchResultsMsg:
+		if msg.seq != m.searchSeq {
+			return m, nil // a newer query is already in flight
+		}
+		m.searchResults = msg.hits
+		m.searchErr = msg.err
+		if m.searchCursor >= len(m.searchResults) {
+			m.searchCursor = max(len(m.searchResults)-1, 0)
+		}
+		return m, nil
 
 	case projectCreatedMsg:
 		if msg.err != nil {
@@ -297,6 +544,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateNoteList(msg)
 	case screenEdit:
 		return m.updateEdit(msg)
+	case screenSearch:
+		return m.updateSearch(msg)
+	case screenHistory:
+		return m.updateHistory(msg)
+	case screenTrash:
+		return m.updateTrash(msg)
+	case screenTags:
+		return m.updateTags(msg)
+	case screenReport:
+		return m.updateReport(msg)
+	case screenAsk:
+		return m.updateAsk(msg)
 	}
 
 	return m, nil
@@ -315,6 +574,18 @@ func (m Model) View() string {
 		content = m.viewNoteList()
 	case screenEdit:
 		content = m.viewEdit()
+	case screenSearch:
+		content = m.viewSearch()
+	case screenHistory:
+		content = m.viewHistory()
+	case screenTrash:
+		content = m.viewTrash()
+	case screenTags:
+		content = m.viewTags()
+	case screenReport:
+		content = m.viewReport()
+	case screenAsk:
+		content = m.viewAsk()
 	}
 
 	return appStyle.MaxWidth(m.width).MaxHeight(m.height).Render(content)
@@ -323,10 +594,21 @@ func (m Model) View() string {
 // --- Screen: Project List ---
 
 func (m Model) updateProjectList(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if m.creatingNew {
+	if m.creatingNew || m.renamingProject {
 		return m.updateCreateProject(msg)
 	}
 
+	if m.confirmDeleteProject {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			m.confirmDeleteProject = false
+			if msg.String() == "y" && len(m.projects) > 0 {
+				return m, m.deleteProject(m.projects[m.projectCursor])
+			}
+			m.statusMsg = ""
+		}
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -347,13 +629,45 @@ func (m Model) updateProjectList(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.menuCursor = 0
 				m.statusMsg = ""
 				m.reminders = nil
-				return m, tea.Batch(m.loadTodayNote(), m.loadReminders())
+				return m, tea.Batch(m.loadTodayNote(), m.loadReminders(), m.loadTimer())
 			}
+		case "/":
+			return m.enterSearch()
+		case "T":
+			return m.enterTags()
+		case "R":
+			return m.enterReport()
+		case "?":
+			return m.enterAsk()
+		case "t":
+			return m.enterTrash()
 		case "n":
 			m.creatingNew = true
 			m.newNameInput.Reset()
 			m.newNameInput.Focus()
 			return m, m.newNameInput.Cursor.BlinkCmd()
+		case "r":
+			if len(m.projects) > 0 {
+				m.renamingProject = true
+				m.newNameInput.Reset()
+				m.newNameInput.SetValue(m.projectTitle(m.projects[m.projectCursor]))
+				m.newNameInput.Focus()
+				return m, m.newNameInput.Cursor.BlinkCmd()
+			}
+		case "d":
+			if len(m.projects) > 0 {
+				m.confirmDeleteProject = true
+				m.statusMsg = "Delete " + m.projects[m.projectCursor] + "? It will be moved to the trash. [y/N]"
+				m.statusErr = true
+			}
+		case "a":
+			if len(m.projects) > 0 {
+				name := m.projects[m.projectCursor]
+				return m, m.setProjectArchived(name, !m.projectMeta[name].Archived())
+			}
+		case "A":
+			m.showArchived = !m.showArchived
+			return m, m.loadProjects
 		}
 	}
 
@@ -366,14 +680,19 @@ func (m Model) updateCreateProject(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "esc":
 			m.creatingNew = false
+			m.renamingProject = false
 			return m, nil
 		case "enter":
 			name := m.newNameInput.Value()
-			if name != "" {
-				m.creatingNew = false
-				return m, m.createProject(name)
+			if name == "" {
+				return m, nil
 			}
-			return m, nil
+			if m.renamingProject {
+				m.renamingProject = false
+				return m, m.renameProject(m.projects[m.projectCursor], name)
+			}
+			m.creatingNew = false
+			return m, m.createProject(name)
 		}
 	}
 
@@ -395,10 +714,22 @@ func (m Model) viewProjectList() string {
 	}
 
 	for i, p := range m.projects {
+		meta := m.projectMeta[p]
+		bullet := "  "
+		if meta.Color != "" {
+			bullet = lipgloss.NewStyle().Foreground(lipgloss.Color(meta.Color)).Render("●") + " "
+		}
+		suffix := ""
+		if meta.Description != "" {
+			suffix += mutedStyle.Render("  " + meta.Description)
+		}
+		if meta.Archived() {
+			suffix += mutedStyle.Render(" (archived)")
+		}
 		if i == m.projectCursor {
-			s += selectedItemStyle.Render("  > "+p) + "\n"
+			s += selectedItemStyle.Render("  > ") + bullet + selectedItemStyle.Render(m.projectTitle(p)) + suffix + "\n"
 		} else {
-			s += normalItemStyle.Render("    "+p) + "\n"
+			s += normalItemStyle.Render("    ") + bullet + normalItemStyle.Render(m.projectTitle(p)) + suffix + "\n"
 		}
 	}
 
@@ -407,6 +738,9 @@ func (m Model) viewProjectList() string {
 	if m.creatingNew {
 		s += "Project name: " + m.newNameInput.View() + "\n"
 		s += helpBarStyle.Render(helpEntry("enter", "create") + "  " + helpEntry("esc", "cancel"))
+	} else if m.renamingProject {
+		s += "New name: " + m.newNameInput.View() + "\n"
+		s += helpBarStyle.Render(helpEntry("enter", "rename") + "  " + helpEntry("esc", "cancel"))
 	} else {
 		if m.statusMsg != "" {
 			if m.statusErr {
@@ -415,10 +749,23 @@ func (m Model) viewProjectList() string {
 				s += successStyle.Render(m.statusMsg) + "\n"
 			}
 		}
+		archivedHint := "show archived"
+		if m.showArchived {
+			archivedHint = "hide archived"
+		}
 		s += helpBarStyle.Render(
 			helpEntry("↑/↓", "navigate") + "  " +
 				helpEntry("enter", "select") + "  " +
 				helpEntry("n", "new project") + "  " +
+				helpEntry("r", "rename") + "  " +
+				helpEntry("d", "delete") + "  " +
+				helpEntry("a", "archive") + "\n" +
+				helpEntry("A", archivedHint) + "  " +
+				helpEntry("/", "search") + "  " +
+				helpEntry("T", "tags") + "  " +
+				helpEntry("R", "timesheet") + "  " +
+				helpEntry("?", "ask") + "  " +
+				helpEntry("t", "trash") + "  " +
 				helpEntry("q", "quit"),
 		)
 	}
@@ -456,6 +803,13 @@ func (m Model) updateProjectView(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleMenuSelect()
 		case "e":
 			return m.enterEditMode(storage.CategoryDaily, storage.TodayName())
+		case "E":
+			// The selected reminder's summary, or else today's note.
+			if m.menuCursor < len(m.reminders) {
+				r := m.reminders[m.menuCursor]
+				return m, m.openInEditor(r.Category, r.Name)
+			}
+			return m, m.openInEditor(storage.CategoryDaily, storage.TodayName())
 		case "d":
 			return m.enterNoteList(storage.CategoryDaily)
 		case "w":
@@ -466,6 +820,16 @@ func (m Model) updateProjectView(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.enterNoteList(storage.CategoryQuarterly)
 		case "y":
 			return m.enterNoteList(storage.CategoryYearly)
+		case "/":
+			return m.enterSearch()
+		case "T":
+			return m.enterTags()
+		case "R":
+			return m.enterReport()
+		case "?":
+			return m.enterAsk()
+		case "t":
+			return m, m.toggleTimer()
 		}
 	}
 
@@ -494,7 +858,18 @@ func (m Model) viewProjectView() string {
 	leftWidth, rightWidth, paneHeight := m.projectViewLayout()
 
 	// Left pane: menu
-	leftContent := headerStyle.Render(m.currentProject) + "\n\n"
+	leftContent := headerStyle.Render(m.projectTitle(m.currentProject)) + "\n"
+	if desc := m.projectMeta[m.currentProject].Description; desc != "" {
+		leftContent += mutedStyle.Width(leftWidth-4).Render(desc) + "\n"
+	}
+	if m.timer != nil {
+		if m.timer.Project == m.currentProject {
+			leftContent += successStyle.Render("⏱ Tracking since "+m.timer.Start.Local().Format("15:04")) + "\n"
+		} else {
+			leftContent += mutedStyle.Width(leftWidth-4).Render("⏱ Timer running on "+m.projectTitle(m.timer.Project)) + "\n"
+		}
+	}
+	leftContent += "\n"
 
 	// Reminders (navigable)
 	if len(m.reminders) > 0 {
@@ -526,7 +901,7 @@ func (m Model) viewProjectView() string {
 		Render(leftContent)
 
 	// Right pane: today's note preview
-	rightContent := previewHeaderStyle.Render("📅 "+storage.TodayName()) + "\n\n"
+	rightContent := previewHeaderStyle.Render("📅 "+storage.TodayName()+m.timeLabel(storage.TodayName(), m.todayNote)) + "\n\n"
 	if m.todayNote == "" {
 		rightContent += mutedStyle.Render("No entry for today yet.\nPress [e] to start writing.")
 	} else if m.todayNoteRendered == "" {
@@ -557,9 +932,18 @@ func (m Model) viewProjectView() string {
 	}
 
 	// Help
+	timerHint := "start timer"
+	if m.timer != nil && m.timer.Project == m.currentProject {
+		timerHint = "stop timer"
+	}
 	help := helpBarStyle.Render(
 		helpEntry("↑/↓", "navigate") + "  " +
 			helpEntry("enter", "select") + "  " +
+			helpEntry("E", "$EDITOR") + "  " +
+			helpEntry("t", timerHint) + "  " +
+			helpEntry("/", "search") + "  " +
+			helpEntry("T", "tags") + "  " +
+			helpEntry("?", "ask") + "  " +
 			helpEntry("b", "back") + "  " +
 			helpEntry("q", "quit"),
 	)
@@ -605,9 +989,17 @@ func (m Model) updateNoteList(msg tea.Msg) (tea.Model, tea.Cmd) {
 				note := m.notes[m.noteCursor]
 				return m.enterEditMode(m.noteCategory, note.Name)
 			}
+		case "E":
+			if len(m.notes) > 0 {
+				return m, m.openInEditor(m.noteCategory, m.notes[m.noteCursor].Name)
+			}
 		case "n":
 			name := storage.DefaultNameForCategory(m.noteCategory)
 			return m.enterEditMode(m.noteCategory, name)
+		case "h":
+			return m.enterHistory()
+		case "/":
+			return m.enterSearch()
 		}
 	}
 
@@ -639,7 +1031,11 @@ func (m Model) viewNoteList() string {
 	// Right pane: preview
 	rightContent := ""
 	if len(m.notes) > 0 && m.noteCursor < len(m.notes) {
-		rightContent += previewHeaderStyle.Render("📄 "+m.notes[m.noteCursor].Name) + "\n\n"
+		header := "📄 " + m.notes[m.noteCursor].Name
+		if m.noteCategory == storage.CategoryDaily {
+			header += m.timeLabel(m.notes[m.noteCursor].Name, m.previewNote)
+		}
+		rightContent += previewHeaderStyle.Render(header) + "\n\n"
 		if m.previewNote == "" {
 			rightContent += mutedStyle.Render("(empty)")
 		} else if m.previewNoteRendered == "" {
@@ -658,7 +1054,7 @@ func (m Model) viewNoteList() string {
 
 	body := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
 
-	title := titleStyle.Render("🍵 teatime — " + m.currentProject)
+	title := titleStyle.Render("🍵 teatime — " + m.projectTitle(m.currentProject))
 
 	status := ""
 	if m.statusMsg != "" {
@@ -672,7 +1068,10 @@ func (m Model) viewNoteList() string {
 	help := helpBarStyle.Render(
 		helpEntry("↑/↓", "navigate") + "  " +
 			helpEntry("enter", "edit") + "  " +
+			helpEntry("E", "$EDITOR") + "  " +
 			helpEntry("n", "new note") + "  " +
+			helpEntry("h", "history") + "  " +
+			helpEntry("/", "search") + "  " +
 			helpEntry("b", "back") + "  " +
 			helpEntry("q", "quit"),
 	)
@@ -683,11 +1082,26 @@ func (m Model) viewNoteList() string {
 // --- Screen: Edit ---
 
 func (m Model) enterEditMode(category storage.Category, name string) (tea.Model, tea.Cmd) {
+	return m.enterEditModeAt(category, name, 0)
+}
+
+// enterEditModeAt opens a note for editing with the cursor on the given
+// 1-based line once the content has loaded (0 leaves it at the end).
+func (m Model) enterEditModeAt(category storage.Category, name string, line int) (tea.Model, tea.Cmd) {
 	m.screen = screenEdit
 	m.editCategory = category
 	m.editNoteName = name
+	m.editJumpLine = line
+	m.editConflict = nil
 	m.editDirty = false
 	m.editRef = ""
+	m.editRefErr = nil
+	m.editPrompt = ""
+	m.editSession++
+	m.editAutosaved = ""
+	m.editRecovery = nil
+	m.editConfirmDiscard = false
+	m.editPreview = false
 	m.editFocusLeft = true
 	m.statusMsg = ""
 
@@ -754,6 +1168,7 @@ func (m Model) enterEditMode(category storage.Category, name string) (tea.Model,
 	cmds := []tea.Cmd{
 		m.loadNoteContent(m.currentProject, category, name, "edit"),
 		m.editTextarea.Cursor.BlinkCmd(),
+		autosaveTick(m.editSession),
 	}
 
 	// Load reference content for summary notes
@@ -767,20 +1182,67 @@ func (m Model) enterEditMode(category storage.Category, name string) (tea.Model,
 func (m Model) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 	hasSplitPane := m.editCategory != storage.CategoryDaily
 
+	if m.editConfirmDiscard {
+		return m.updateEditConfirmDiscard(msg)
+	}
+	if m.editConflict != nil {
+		return m.updateEditConflict(msg)
+	}
+	if m.editRecovery != nil {
+		return m.updateEditRecovery(msg)
+	}
+
+	if key, ok := msg.(tea.KeyMsg); ok && m.drafting {
+		// The textarea is locked while the draft streams in.
+		switch key.String() {
+		case "esc":
+			m = m.stopDraft()
+			m.statusMsg = "Draft stopped"
+			m.statusErr = false
+			return m, nil
+		case "ctrl+c":
+			m = m.stopDraft()
+		default:
+			return m, nil
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
-			// Save and go back; loadTodayNote will be triggered by noteSavedMsg
+			// Save and go back; loadTodayNote will be triggered by noteSavedMsg.
+			// The save is refused if the note changed on disk since it was loaded.
 			content := m.editTextarea.Value()
 			m.screen = screenProjectView
-			return m, m.saveNote(m.currentProject, m.editCategory, m.editNoteName, content)
+			return m, m.saveNote(m.currentProject, m.editCategory, m.editNoteName, content, m.editVersion)
 		case "ctrl+c":
-			// Abort without saving
+			// Abort without saving, after confirming if there is anything to lose.
+			if m.editDirty {
+				m.editConfirmDiscard = true
+				m.statusMsg = "Discard unsaved changes?"
+				m.statusErr = true
+				return m, nil
+			}
 			m.screen = screenProjectView
 			m.statusMsg = "Edit cancelled"
 			m.statusErr = false
-			return m, nil
+			return m, m.deleteAutosave(m.currentProject, m.editCategory, m.editNoteName)
+		case "ctrl+g":
+			if hasSplitPane {
+				return m.startDraft()
+			}
+		case "ctrl+r":
+			if hasSplitPane {
+				m.editPreview = !m.editPreview
+				if m.editPreview {
+					m.editViewport.SetContent(m.draftPreview())
+				} else {
+					m.editViewport.SetContent(m.editRefRendered)
+				}
+				m.editViewport.GotoTop()
+				return m, nil
+			}
 		case "tab":
 			if hasSplitPane {
 				m.editFocusLeft = !m.editFocusLeft
@@ -796,8 +1258,11 @@ func (m Model) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	if m.editFocusLeft {
 		var cmd tea.Cmd
+		before := m.editTextarea.Value()
 		m.editTextarea, cmd = m.editTextarea.Update(msg)
-		m.editDirty = true
+		if m.editTextarea.Value() != before {
+			m.editDirty = true
+		}
 		return m, cmd
 	}
 
@@ -807,10 +1272,68 @@ func (m Model) updateEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateEditConflict handles the prompt shown when a save was refused
+// because the note changed on disk.
+func (m Model) updateEditConflict(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	conflict := m.editConflict
+
+	switch key.String() {
+	case "o":
+		// Overwrite the disk version with ours. The save is checked against
+		// the version shown in the conflict, so a further outside change
+		// conflicts again rather than being lost unseen.
+		m.editConflict = nil
+		m.screen = screenProjectView
+		return m, m.saveNote(m.currentProject, m.editCategory, m.editNoteName, m.editTextarea.Value(), conflict.Version)
+	case "r":
+		// Drop our changes and load the disk version.
+		m.editTextarea.SetValue(conflict.Disk)
+		m.editBase = conflict.Disk
+		m.editVersion = conflict.Version
+		m.editConflict = nil
+		m.editDirty = false
+		m.statusMsg = "Reloaded from disk"
+		m.statusErr = false
+		return m, nil
+	case "m":
+		// Merge both versions; the result is saved against the disk version.
+		merged, conflicts := storage.Merge3(m.editBase, m.editTextarea.Value(), conflict.Disk)
+		m.editTextarea.SetValue(merged)
+		m.editBase = conflict.Disk
+		m.editVersion = conflict.Version
+		m.editConflict = nil
+		m.editDirty = true
+		if conflicts {
+			m.statusMsg = "Merged with conflicts — resolve the <<<<<<< markers, then esc to save"
+			m.statusErr = true
+		} else {
+			m.statusMsg = "Merged cleanly — review, then esc to save"
+			m.statusErr = false
+		}
+		return m, nil
+	case "esc":
+		// Keep editing; the next save will hit the same conflict.
+		m.editConflict = nil
+		m.statusMsg = ""
+		return m, nil
+	case "ctrl+c":
+		// Discarding loses our changes; updateEditConfirmDiscard also clears the conflict.
+		m.editConfirmDiscard = true
+		m.statusMsg = "Discard unsaved changes?"
+		m.statusErr = true
+		return m, nil
+	}
+	return m, nil
+}
+
 func (m Model) viewEdit() string {
 	hasSplitPane := m.editCategory != storage.CategoryDaily
 
-	title := titleStyle.Render("🍵 teatime — " + m.currentProject + " — " + m.editNoteName + " [edit]")
+	title := titleStyle.Render("🍵 teatime — " + m.projectTitle(m.currentProject) + " — " + m.editNoteName + " [edit]")
 
 	catLabel := storage.CategoryLabel(m.editCategory)
 	subtitle := mutedStyle.Render(catLabel)
@@ -838,6 +1361,9 @@ func (m Model) viewEdit() string {
 
 		// Right pane: reference content (scrollable viewport)
 		refLabel := paneHeaderStyle.Render(referenceLabel(m.editCategory))
+		if m.editPreview {
+			refLabel = paneHeaderStyle.Render("🔒 Sent to LLM")
+		}
 		rightContent := refLabel + "\n" + m.editViewport.View()
 		var rightPane string
 		if !m.editFocusLeft {
@@ -878,13 +1404,38 @@ func (m Model) viewEdit() string {
 	}
 
 	var help string
-	if hasSplitPane {
+	if m.editConfirmDiscard {
+		help = helpBarStyle.MaxWidth(maxHelpWidth).Render(
+			helpEntry("y", "discard") + "  " +
+				helpEntry("n", "keep editing"),
+		)
+	} else if m.editRecovery != nil {
+		help = helpBarStyle.MaxWidth(maxHelpWidth).Render(
+			helpEntry("r", "recover") + "  " +
+				helpEntry("d", "discard them") + "  " +
+				helpEntry("ctrl+c", "close"),
+		)
+	} else if m.editConflict != nil {
+		help = helpBarStyle.MaxWidth(maxHelpWidth).Render(
+			helpEntry("o", "overwrite") + "  " +
+				helpEntry("r", "reload from disk") + "  " +
+				helpEntry("m", "merge") + "  " +
+				helpEntry("esc", "keep editing"),
+		)
+	} else if m.drafting {
+		help = helpBarStyle.MaxWidth(maxHelpWidth).Render(
+			helpEntry("esc", "stop drafting") + "  " +
+				helpEntry("ctrl+c", "discard"),
+		)
+	} else if hasSplitPane {
 		focusHint := "ref"
 		if !m.editFocusLeft {
 			focusHint = "editor"
 		}
 		help = helpBarStyle.MaxWidth(maxHelpWidth).Render(
 			helpEntry("tab", focusHint) + "  " +
+				helpEntry("ctrl+g", "draft with LLM") + "  " +
+				helpEntry("ctrl+r", "preview") + "  " +
 				helpEntry("esc", "save"+dirtyMarker) + "  " +
 				helpEntry("ctrl+c", "discard"),
 		)
@@ -918,18 +1469,25 @@ func referenceLabel(cat storage.Category) string {
 
 type projectsLoadedMsg struct {
 	projects []string
+	meta     map[string]storage.ProjectMeta
 	err      error
 }
 
 type noteLoadedMsg struct {
-	content string
-	target  string // "today", "preview", or "edit"
-	err     error
+	content     string
+	version     storage.NoteVersion
+	target      string // "today", "preview", or "edit"
+	err         error
+	template    string // for "edit" of a note that doesn't exist yet
+	templateErr error
+	draft       *storage.Draft // for "edit": autosaved changes that were never saved
 }
 
 type refContentLoadedMsg struct {
-	content string
-	err     error
+	content   string
+	err       error
+	prompt    string // the note's prompt template, rendered
+	promptErr error
 }
 
 type markdownRenderedMsg struct {
@@ -944,13 +1502,16 @@ type notesListedMsg struct {
 
 func (m Model) renderMarkdownCmd(content string, width int, target string) tea.Cmd {
 	return func() tea.Msg {
-		rendered := renderMarkdown(width, content)
+		rendered := renderNote(width, content)
 		return markdownRenderedMsg{content: rendered, target: target}
 	}
 }
 
 type noteSavedMsg struct {
-	err error
+	project  string
+	category storage.Category
+	name     string
+	err      error
 }
 
 type projectCreatedMsg struct {
@@ -958,8 +1519,34 @@ type projectCreatedMsg struct {
 }
 
 func (m Model) loadProjects() tea.Msg {
-	projects, err := m.store.ListProjects()
-	return projectsLoadedMsg{projects: projects, err: err}
+	list := m.store.ListProjects
+	if m.showArchived {
+		list = m.store.ListAllProjects
+	}
+	projects, err := list()
+	if err != nil {
+		return projectsLoadedMsg{err: err}
+	}
+	// Metadata is loaded for every project, archived or not, so titles
+	// are right even when a search result opens an archived project.
+	all, err := m.store.ListAllProjects()
+	if err != nil {
+		return projectsLoadedMsg{err: err}
+	}
+	meta := make(map[string]storage.ProjectMeta, len(all))
+	for _, p := range all {
+		pm, err := m.store.ProjectMeta(p)
+		if err != nil {
+			return projectsLoadedMsg{err: err}
+		}
+		meta[p] = pm
+	}
+	return projectsLoadedMsg{projects: projects, meta: meta}
+}
+
+// projectTitle returns a project's display name.
+func (m Model) projectTitle(name string) string {
+	return m.projectMeta[name].Title(name)
 }
 
 func (m Model) loadTodayNote() tea.Cmd {
@@ -971,15 +1558,24 @@ func (m Model) loadTodayNote() tea.Cmd {
 
 func (m Model) loadNoteContent(project string, category storage.Category, name string, target string) tea.Cmd {
 	return func() tea.Msg {
-		content, err := m.store.ReadNote(project, category, name)
-		return noteLoadedMsg{content: content, target: target, err: err}
+		content, version, err := m.store.ReadNoteVersion(project, category, name)
+		msg := noteLoadedMsg{content: content, version: version, target: target, err: err}
+		if target == "edit" && err == nil && !version.Exists {
+			msg.template, msg.templateErr = m.store.NoteTemplate(project, category, name)
+		}
+		if target == "edit" && err == nil {
+			// Best-effort: an unreadable draft is simply not offered.
+			msg.draft, _ = m.store.ReadDraft(project, category, name)
+		}
+		return msg
 	}
 }
 
 func (m Model) loadReferenceContent(project string, category storage.Category, name string) tea.Cmd {
 	return func() tea.Msg {
 		content, err := m.store.GatherReferenceContent(project, category, name)
-		return refContentLoadedMsg{content: content, err: err}
+		prompt, promptErr := m.store.PromptTemplate(project, category, name)
+		return refContentLoadedMsg{content: content, err: err, prompt: prompt, promptErr: promptErr}
 	}
 }
 
@@ -990,10 +1586,10 @@ func (m Model) listNotes(project string, category storage.Category) tea.Cmd {
 	}
 }
 
-func (m Model) saveNote(project string, category storage.Category, name string, content string) tea.Cmd {
+func (m Model) saveNote(project string, category storage.Category, name string, content string, base storage.NoteVersion) tea.Cmd {
 	return func() tea.Msg {
-		err := m.store.WriteNote(project, category, name, content)
-		return noteSavedMsg{err: err}
+		err := m.store.WriteNote(project, category, name, content, base)
+		return noteSavedMsg{project: project, category: category, name: name, err: err}
 	}
 }
 
@@ -1004,6 +1600,36 @@ func (m Model) createProject(name string) tea.Cmd {
 	}
 }
 
+type projectChangedMsg struct {
+	status string
+	err    error
+}
+
+func (m Model) renameProject(oldName, newName string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.store.RenameProject(oldName, newName)
+		return projectChangedMsg{status: "Project renamed ✓", err: err}
+	}
+}
+
+func (m Model) deleteProject(name string) tea.Cmd {
+	return func() tea.Msg {
+		err := m.store.DeleteProject(name)
+		return projectChangedMsg{status: "Moved " + name + " to the trash", err: err}
+	}
+}
+
+func (m Model) setProjectArchived(name string, archived bool) tea.Cmd {
+	return func() tea.Msg {
+		err := m.store.SetProjectArchived(name, archived)
+		status := "Unarchived " + name
+		if archived {
+			status = "Archived " + name
+		}
+		return projectChangedMsg{status: status, err: err}
+	}
+}
+
 type remindersLoadedMsg struct {
 	reminders []storage.Reminder
 	err       error
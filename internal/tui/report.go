@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gabrielfornes/teatime/internal/report"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// --- Screen: Timesheet report ---
+
+func (m Model) enterReport() (tea.Model, tea.Cmd) {
+	m.reportReturn = m.screen
+	m.screen = screenReport
+	m.statusMsg = ""
+	if m.reportPeriod == "" {
+		m.reportCategory = storage.CategoryWeekly
+		m.reportPeriod = storage.CurrentWeekName()
+	}
+	m.reportViewport = viewport.New(m.reportViewportSize())
+	return m, m.loadTimesheet(m.reportCategory, m.reportPeriod)
+}
+
+// reportViewportSize returns the width and height of the timesheet table.
+func (m Model) reportViewportSize() (int, int) {
+	return max(m.width-6, 20), max(m.height-8, 3)
+}
+
+func (m Model) updateReport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "b", "esc":
+		m.screen = m.reportReturn
+		m.statusMsg = ""
+		return m, nil
+	case "left", "h", "right", "l":
+		n := 1
+		if k := key.String(); k == "left" || k == "h" {
+			n = -1
+		}
+		period, err := storage.ShiftPeriod(m.reportCategory, m.reportPeriod, n)
+		if err != nil {
+			return m, nil
+		}
+		m.reportPeriod = period
+		return m, m.loadTimesheet(m.reportCategory, m.reportPeriod)
+	case "w":
+		m.reportCategory, m.reportPeriod = storage.CategoryWeekly, storage.CurrentWeekName()
+		return m, m.loadTimesheet(m.reportCategory, m.reportPeriod)
+	case "m":
+		m.reportCategory, m.reportPeriod = storage.CategoryMonthly, storage.CurrentMonthName()
+		return m, m.loadTimesheet(m.reportCategory, m.reportPeriod)
+	default:
+		// up/down/pgup/pgdn scroll long months
+		var cmd tea.Cmd
+		m.reportViewport, cmd = m.reportViewport.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m Model) viewReport() string {
+	title := titleStyle.Render("🍵 teatime — timesheet")
+
+	status := ""
+	if m.statusMsg != "" {
+		if m.statusErr {
+			status = errorStyle.Render(m.statusMsg)
+		} else {
+			status = successStyle.Render(m.statusMsg)
+		}
+	}
+
+	help := helpBarStyle.Render(
+		helpEntry("←/→", "previous/next") + "  " +
+			helpEntry("w", "this week") + "  " +
+			helpEntry("m", "this month") + "  " +
+			helpEntry("↑/↓", "scroll") + "  " +
+			helpEntry("b", "back") + "  " +
+			helpEntry("q", "quit"),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, m.reportViewport.View(), status, help)
+}
+
+// --- Report commands ---
+
+type timesheetLoadedMsg struct {
+	period string
+	sheet  *storage.Timesheet
+	err    error
+}
+
+func (m Model) loadTimesheet(category storage.Category, period string) tea.Cmd {
+	return func() tea.Msg {
+		sheet, err := m.store.Timesheet(category, period)
+		return timesheetLoadedMsg{period: period, sheet: sheet, err: err}
+	}
+}
+
+// updateReportMsg handles the report screen's async results.
+func (m Model) updateReportMsg(msg timesheetLoadedMsg) (Model, tea.Cmd) {
+	if msg.period != m.reportPeriod {
+		return m, nil // already moved on to another period
+	}
+	if msg.err != nil {
+		m.statusMsg = "Error building timesheet: " + msg.err.Error()
+		m.statusErr = true
+		m.reportViewport.SetContent("")
+		return m, nil
+	}
+	var b strings.Builder
+	if err := report.WriteTable(&b, msg.sheet); err != nil {
+		m.statusMsg = "Error building timesheet: " + err.Error()
+		m.statusErr = true
+	}
+	m.reportViewport.SetContent(b.String())
+	m.reportViewport.GotoTop()
+	return m, nil
+}
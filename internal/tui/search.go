@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// --- Screen: Search ---
+
+func (m Model) enterSearch() (tea.Model, tea.Cmd) {
+	m.searchReturn = m.screen
+	m.screen = screenSearch
+	m.statusMsg = ""
+	m.searchInput.Focus()
+	// Keep the previous query and results so "/" then esc is cheap,
+	// but re-run the query in case notes changed in the meantime.
+	m.searchSeq++
+	return m, tea.Batch(m.searchInput.Cursor.BlinkCmd(), m.runSearch(m.searchSeq, m.searchInput.Value()))
+}
+
+func (m Model) updateSearch(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.screen = m.searchReturn
+			m.searchInput.Blur()
+			return m, nil
+		case "up", "ctrl+p":
+			if m.searchCursor > 0 {
+				m.searchCursor--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.searchCursor < len(m.searchResults)-1 {
+				m.searchCursor++
+			}
+			return m, nil
+		case "enter":
+			if len(m.searchResults) == 0 {
+				return m, nil
+			}
+			hit := m.searchResults[m.searchCursor]
+			m.searchInput.Blur()
+			m.currentProject = hit.Project
+			m.reminders = nil
+			m.menuCursor = 0
+			next, cmd := m.enterEditModeAt(hit.Category, hit.Name, hit.Line)
+			// The project may have changed, so refresh what the project view shows
+			// for when the editor is closed.
+			return next, tea.Batch(cmd, m.loadTodayNote(), m.loadReminders(), m.loadTimer())
+		}
+	}
+
+	before := m.searchInput.Value()
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if query := m.searchInput.Value(); query != before {
+		m.searchSeq++
+		return m, tea.Batch(cmd, m.runSearch(m.searchSeq, query))
+	}
+	return m, cmd
+}
+
+func (m Model) viewSearch() string {
+	title := titleStyle.Render("🍵 teatime — search")
+
+	s := "/ " + m.searchInput.View() + "\n\n"
+
+	query := strings.TrimSpace(m.searchInput.Value())
+	switch {
+	case m.searchErr != nil:
+		s += errorStyle.Render("Error: "+m.searchErr.Error()) + "\n"
+	case query == "":
+		s += mutedStyle.Render("Type to search every note in every project.") + "\n"
+	case len(m.searchResults) == 0:
+		s += mutedStyle.Render("No matches.") + "\n"
+	default:
+		// Show a window of results around the cursor.
+		visible := m.height - 10
+		if visible < 3 {
+			visible = 3
+		}
+		start := 0
+		if m.searchCursor >= visible {
+			start = m.searchCursor - visible + 1
+		}
+		end := start + visible
+		if end > len(m.searchResults) {
+			end = len(m.searchResults)
+		}
+
+		width := m.width - 6
+		for i := start; i < end; i++ {
+			hit := m.searchResults[i]
+			loc := hit.Location()
+			snippetWidth := width - len(loc) - 6
+			line := searchLocationStyle.Render(loc) + "  " + highlightSnippet(hit.Text, hit.Matches, snippetWidth)
+			if i == m.searchCursor {
+				s += selectedItemStyle.Render("  > ") + line + "\n"
+			} else {
+				s += "    " + line + "\n"
+			}
+		}
+		s += mutedStyle.Render("\n"+strconv.Itoa(len(m.searchResults))+" matching lines") + "\n"
+	}
+
+	help := helpBarStyle.Render(
+		helpEntry("↑/↓", "navigate") + "  " +
+			helpEntry("enter", "open") + "  " +
+			helpEntry("esc", "back"),
+	)
+
+	return title + "\n" + s + help
+}
+
+// highlightSnippet trims text to about width bytes around the first match
+// and renders every match with matchStyle.
+func highlightSnippet(text string, matches [][2]int, width int) string {
+	if width < 20 {
+		width = 20
+	}
+
+	// Pick a window of the line that contains the first match.
+	lead := len(text) - len(strings.TrimLeft(text, " \t"))
+	start, end := lead, len(text)
+	if len(matches) > 0 && matches[0][0]-start > width/3 {
+		start = matches[0][0] - width/3
+	}
+	if end-start > width {
+		end = start + width
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	var b strings.Builder
+	if start > lead {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, r := range matches {
+		if r[1] <= pos || r[0] >= end {
+			continue
+		}
+		from, to := max(r[0], pos), min(r[1], end)
+		b.WriteString(text[pos:from])
+		b.WriteString(matchStyle.Render(text[from:to]))
+		pos = to
+	}
+	b.WriteString(text[pos:end])
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// jumpToLine moves the textarea cursor to the start of the given 0-based line.
+func jumpToLine(ta *textarea.Model, row int) {
+	for ta.Line() > row {
+		ta.CursorUp()
+	}
+	ta.CursorStart()
+}
+
+// --- Search commands ---
+
+type searchResultsMsg struct {
+	seq  int
+	hits []storage.SearchHit
+	err  error
+}
+
+func (m Model) runSearch(seq int, query string) tea.Cmd {
+	return func() tea.Msg {
+		hits, err := m.store.Search(query, storage.DefaultSearchLimit)
+		return searchResultsMsg{seq: seq, hits: hits, err: err}
+	}
+}
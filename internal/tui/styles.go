@@ -1,10 +1,12 @@
 package tui
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gabrielfornes/teatime/internal/storage"
 )
 
 // renderMarkdown renders markdown content using glamour.
@@ -31,6 +33,39 @@ func renderMarkdown(width int, content string) string {
 	return strings.TrimSpace(out)
 }
 
+// renderNote renders a note for the preview panes. A frontmatter block is
+// shown as a compact header line instead of raw YAML.
+func renderNote(width int, content string) string {
+	note, err := storage.ParseNote(content)
+	if err != nil || !note.HasFrontmatter() {
+		return renderMarkdown(width, content)
+	}
+
+	var parts []string
+	if len(note.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(note.Tags, " #"))
+	}
+	if note.Hours != 0 {
+		parts = append(parts, strconv.FormatFloat(note.Hours, 'f', -1, 64)+"h")
+	}
+	if note.Mood != "" {
+		parts = append(parts, "mood: "+note.Mood)
+	}
+	var header []string
+	if len(parts) > 0 {
+		header = append(header, frontmatterStyle.Width(width-2).Render(strings.Join(parts, "  ·  ")))
+	}
+	for _, link := range note.Links {
+		header = append(header, frontmatterStyle.Width(width-2).Render("→ "+link))
+	}
+
+	body := renderMarkdown(width, note.Body)
+	if len(header) == 0 {
+		return body
+	}
+	return "  " + strings.Join(header, "\n  ") + "\n\n" + body
+}
+
 // Colors
 var (
 	colorPrimary   = lipgloss.Color("#E0A458") // warm tea gold
@@ -137,6 +172,31 @@ var (
 				Foreground(colorSecondary).
 				Bold(true).
 				MarginBottom(1)
+
+	// frontmatterStyle renders a note's tags, hours and mood above its body.
+	frontmatterStyle = lipgloss.NewStyle().
+				Foreground(colorMuted).
+				Italic(true)
+)
+
+// Diffs
+var (
+	diffInsertStyle = lipgloss.NewStyle().
+			Foreground(colorSecondary)
+
+	diffDeleteStyle = lipgloss.NewStyle().
+			Foreground(colorDanger)
+)
+
+// Search
+var (
+	searchLocationStyle = lipgloss.NewStyle().
+				Foreground(colorSecondary)
+
+	matchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#1E1E1E")).
+			Background(colorPrimary).
+			Bold(true)
 )
 
 // helpEntry renders a single "[key] description" help item.
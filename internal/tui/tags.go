@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// --- Screen: Tags ---
+
+func (m Model) enterTags() (tea.Model, tea.Cmd) {
+	m.tagsReturn = m.screen
+	m.screen = screenTags
+	m.tagsFocusNotes = false
+	m.statusMsg = ""
+	return m, m.loadTags
+}
+
+func (m Model) updateTags(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "b", "esc":
+		if m.tagsFocusNotes {
+			m.tagsFocusNotes = false
+			return m, nil
+		}
+		m.screen = m.tagsReturn
+		m.statusMsg = ""
+		return m, nil
+	case "tab", "right", "l", "left", "h":
+		if len(m.tagNotes) > 0 {
+			m.tagsFocusNotes = !m.tagsFocusNotes
+		}
+	case "up", "k":
+		if m.tagsFocusNotes {
+			if m.tagNotesCursor > 0 {
+				m.tagNotesCursor--
+			}
+		} else if m.tagsCursor > 0 {
+			m.tagsCursor--
+			return m, m.loadTagNotes(m.tagsList[m.tagsCursor].Tag)
+		}
+	case "down", "j":
+		if m.tagsFocusNotes {
+			if m.tagNotesCursor < len(m.tagNotes)-1 {
+				m.tagNotesCursor++
+			}
+		} else if m.tagsCursor < len(m.tagsList)-1 {
+			m.tagsCursor++
+			return m, m.loadTagNotes(m.tagsList[m.tagsCursor].Tag)
+		}
+	case "enter":
+		if !m.tagsFocusNotes {
+			if len(m.tagNotes) > 0 {
+				m.tagsFocusNotes = true
+			}
+			return m, nil
+		}
+		note := m.tagNotes[m.tagNotesCursor]
+		m.currentProject = note.Project
+		m.reminders = nil
+		m.menuCursor = 0
+		next, cmd := m.enterEditMode(note.Category, note.Name)
+		// As with search, the project may have changed under the editor.
+		return next, tea.Batch(cmd, m.loadTodayNote(), m.loadReminders(), m.loadTimer())
+	}
+	return m, nil
+}
+
+func (m Model) viewTags() string {
+	leftWidth, rightWidth, paneHeight := m.projectViewLayout()
+
+	leftContent := headerStyle.Render("Tags") + "\n\n"
+	if len(m.tagsList) == 0 {
+		leftContent += mutedStyle.Render("No tags yet. Write #tags\nin a note or add a tags:\nlist to its frontmatter.")
+	}
+	for i, t := range m.tagsList {
+		line := "#" + t.Tag
+		count := mutedStyle.Render("  " + strconv.Itoa(t.Count))
+		if i == m.tagsCursor {
+			style := selectedItemStyle
+			if m.tagsFocusNotes {
+				style = normalItemStyle
+			}
+			leftContent += style.Render("  > "+line) + count + "\n"
+		} else {
+			leftContent += normalItemStyle.Render("    "+line) + count + "\n"
+		}
+	}
+
+	leftPane := leftPaneStyle.
+		Width(leftWidth).
+		Height(paneHeight).
+		Render(leftContent)
+
+	rightContent := ""
+	if len(m.tagsList) > 0 {
+		rightContent = previewHeaderStyle.Render("🕑 #"+m.tagsList[m.tagsCursor].Tag+" timeline") + "\n"
+	}
+	year := 0
+	for i, n := range m.tagNotes {
+		if !n.Start.IsZero() && n.Start.Year() != year {
+			year = n.Start.Year()
+			rightContent += paneHeaderStyle.Render(strconv.Itoa(year)) + "\n"
+		}
+		line := n.Name + "  " + m.projectTitle(n.Project)
+		kind := mutedStyle.Render("  " + storage.CategoryLabel(n.Category))
+		if m.tagsFocusNotes && i == m.tagNotesCursor {
+			rightContent += selectedItemStyle.Render("  > "+line) + kind + "\n"
+		} else {
+			rightContent += normalItemStyle.Render("    "+line) + kind + "\n"
+		}
+	}
+
+	rightPane := rightPaneStyle.
+		Width(rightWidth).
+		Height(paneHeight).
+		Render(rightContent)
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
+	title := titleStyle.Render("🍵 teatime — tags")
+
+	status := ""
+	if m.statusMsg != "" {
+		if m.statusErr {
+			status = errorStyle.Render(m.statusMsg)
+		} else {
+			status = successStyle.Render(m.statusMsg)
+		}
+	}
+
+	help := helpBarStyle.Render(
+		helpEntry("↑/↓", "navigate") + "  " +
+			helpEntry("tab", "switch pane") + "  " +
+			helpEntry("enter", "open") + "  " +
+			helpEntry("b", "back") + "  " +
+			helpEntry("q", "quit"),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, body, status, help)
+}
+
+// --- Tag commands ---
+
+type tagsLoadedMsg struct {
+	tags []storage.TagCount
+	err  error
+}
+
+type tagNotesLoadedMsg struct {
+	tag   string
+	notes []storage.TaggedNote
+	err   error
+}
+
+func (m Model) loadTags() tea.Msg {
+	tags, err := m.store.ListTags()
+	return tagsLoadedMsg{tags: tags, err: err}
+}
+
+func (m Model) loadTagNotes(tag string) tea.Cmd {
+	return func() tea.Msg {
+		notes, err := m.store.NotesWithTag(tag)
+		return tagNotesLoadedMsg{tag: tag, notes: notes, err: err}
+	}
+}
+
+// updateTagsMsg handles the tag screen's async results.
+func (m Model) updateTagsMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tagsLoadedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error reading tags: " + msg.err.Error()
+			m.statusErr = true
+		}
+		m.tagsList = msg.tags
+		if m.tagsCursor >= len(m.tagsList) {
+			m.tagsCursor = max(len(m.tagsList)-1, 0)
+		}
+		if len(m.tagsList) == 0 {
+			m.tagNotes = nil
+			return m, nil
+		}
+		return m, m.loadTagNotes(m.tagsList[m.tagsCursor].Tag)
+	case tagNotesLoadedMsg:
+		if len(m.tagsList) == 0 || m.tagsList[m.tagsCursor].Tag != msg.tag {
+			return m, nil // the cursor has moved on
+		}
+		if msg.err != nil {
+			m.statusMsg = "Error reading tags: " + msg.err.Error()
+			m.statusErr = true
+		}
+		m.tagNotes = msg.notes
+		m.tagNotesCursor = 0
+	}
+	return m, nil
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// --- Timer commands ---
+
+type timerLoadedMsg struct {
+	project string
+	active  *storage.Session         // running timer on any project, nil if none
+	tracked map[string]time.Duration // time tracked on project per day
+	err     error
+}
+
+type timerToggledMsg struct {
+	status string
+	err    error
+}
+
+// loadTimer reads the running timer and the current project's tracked
+// time per day.
+func (m Model) loadTimer() tea.Cmd {
+	project := m.currentProject
+	return func() tea.Msg {
+		active, err := m.store.ActiveTimer()
+		if err != nil {
+			return timerLoadedMsg{project: project, err: err}
+		}
+		tracked, err := m.store.TrackedByDay(project)
+		return timerLoadedMsg{project: project, active: active, tracked: tracked, err: err}
+	}
+}
+
+// toggleTimer stops the timer if it is running on the current project and
+// starts it otherwise.
+func (m Model) toggleTimer() tea.Cmd {
+	project := m.currentProject
+	running := m.timer != nil && m.timer.Project == project
+	title := m.projectTitle(project)
+	return func() tea.Msg {
+		if running {
+			session, err := m.store.StopTimer("")
+			return timerToggledMsg{status: "Stopped after " + storage.FormatDuration(session.Duration()), err: err}
+		}
+		stopped, err := m.store.StartTimer(project)
+		status := "Timer started on " + title
+		if stopped != nil {
+			status += " (stopped " + m.projectTitle(stopped.Project) + " after " + storage.FormatDuration(stopped.Duration()) + ")"
+		}
+		return timerToggledMsg{status: status, err: err}
+	}
+}
+
+// updateTimerMsg handles the timer's async results.
+func (m Model) updateTimerMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case timerLoadedMsg:
+		if msg.project != m.currentProject {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMsg = "Error reading time log: " + msg.err.Error()
+			m.statusErr = true
+		}
+		m.timer = msg.active
+		m.tracked = msg.tracked
+	case timerToggledMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+			m.statusErr = true
+		} else {
+			m.statusMsg = msg.status
+			m.statusErr = false
+		}
+		return m, m.loadTimer()
+	}
+	return m, nil
+}
+
+// timeLabel returns "  ⏱ 1h05m" for a day with time spent, or "": the
+// time logged in the day's note content, or else the time tracked with
+// the timer (see storage.DayTime).
+func (m Model) timeLabel(day, content string) string {
+	d := storage.DayTime(m.tracked[day], storage.LoggedTotal(content))
+	if d < time.Minute {
+		return ""
+	}
+	return "  ⏱ " + storage.FormatDuration(d)
+}
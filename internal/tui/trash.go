@@ -0,0 +1,155 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gabrielfornes/teatime/internal/storage"
+)
+
+// --- Screen: Trash ---
+
+func (m Model) enterTrash() (tea.Model, tea.Cmd) {
+	m.screen = screenTrash
+	m.trashCursor = 0
+	m.trashConfirmPurge = false
+	m.statusMsg = ""
+	return m, m.loadTrash
+}
+
+func (m Model) updateTrash(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.trashConfirmPurge {
+		m.trashConfirmPurge = false
+		if key.String() == "y" && len(m.trashItems) > 0 {
+			return m, m.purgeTrashItem(m.trashItems[m.trashCursor])
+		}
+		m.statusMsg = ""
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "b", "esc":
+		m.screen = screenProjectList
+		m.statusMsg = ""
+		return m, m.loadProjects
+	case "up", "k":
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+	case "down", "j":
+		if m.trashCursor < len(m.trashItems)-1 {
+			m.trashCursor++
+		}
+	case "r", "enter":
+		if len(m.trashItems) > 0 {
+			return m, m.restoreTrashItem(m.trashItems[m.trashCursor])
+		}
+	case "x":
+		if len(m.trashItems) > 0 {
+			m.trashConfirmPurge = true
+			m.statusMsg = "Permanently delete " + m.trashItems[m.trashCursor].Label() + "? [y/N]"
+			m.statusErr = true
+		}
+	}
+	return m, nil
+}
+
+func (m Model) viewTrash() string {
+	var s string
+	s += titleStyle.Render("🍵 teatime — trash") + "\n\n"
+
+	if len(m.trashItems) == 0 {
+		s += mutedStyle.Render("The trash is empty.") + "\n"
+	}
+	for i, item := range m.trashItems {
+		icon := "📄 "
+		if item.Kind == storage.TrashProject {
+			icon = "📁 "
+		}
+		line := icon + item.Label()
+		when := mutedStyle.Render("  deleted " + item.DeletedAt.Format("2006-01-02 15:04"))
+		if i == m.trashCursor {
+			s += selectedItemStyle.Render("  > "+line) + when + "\n"
+		} else {
+			s += normalItemStyle.Render("    "+line) + when + "\n"
+		}
+	}
+
+	s += "\n"
+	if m.statusMsg != "" {
+		if m.statusErr {
+			s += errorStyle.Render(m.statusMsg) + "\n"
+		} else {
+			s += successStyle.Render(m.statusMsg) + "\n"
+		}
+	}
+	s += helpBarStyle.Render(
+		helpEntry("↑/↓", "navigate") + "  " +
+			helpEntry("r", "restore") + "  " +
+			helpEntry("x", "delete forever") + "  " +
+			helpEntry("b", "back") + "  " +
+			helpEntry("q", "quit"),
+	)
+	return s
+}
+
+// --- Trash commands ---
+
+type trashLoadedMsg struct {
+	items []storage.TrashItem
+	err   error
+}
+
+type trashChangedMsg struct {
+	status string
+	err    error
+}
+
+func (m Model) loadTrash() tea.Msg {
+	items, err := m.store.ListTrash()
+	return trashLoadedMsg{items: items, err: err}
+}
+
+func (m Model) restoreTrashItem(item storage.TrashItem) tea.Cmd {
+	return func() tea.Msg {
+		err := m.store.RestoreTrash(item.ID)
+		return trashChangedMsg{status: "Restored " + item.Label() + " ✓", err: err}
+	}
+}
+
+func (m Model) purgeTrashItem(item storage.TrashItem) tea.Cmd {
+	return func() tea.Msg {
+		err := m.store.PurgeTrashItem(item.ID)
+		return trashChangedMsg{status: "Deleted " + item.Label() + " permanently", err: err}
+	}
+}
+
+// updateTrashMsg handles the trash screen's async results.
+func (m Model) updateTrashMsg(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case trashLoadedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error reading trash: " + msg.err.Error()
+			m.statusErr = true
+		}
+		m.trashItems = msg.items
+		if m.trashCursor >= len(m.trashItems) {
+			m.trashCursor = max(len(m.trashItems)-1, 0)
+		}
+	case trashChangedMsg:
+		if msg.err != nil {
+			m.statusMsg = "Error: " + msg.err.Error()
+			m.statusErr = true
+		} else {
+			m.statusMsg = msg.status
+			m.statusErr = false
+		}
+		return m, m.loadTrash
+	}
+	return m, nil
+}
@@ -1,26 +1,85 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gabrielfornes/teatime/internal/cli"
+	"github.com/gabrielfornes/teatime/internal/config"
 	"github.com/gabrielfornes/teatime/internal/storage"
 	"github.com/gabrielfornes/teatime/internal/tui"
 )
 
 func main() {
-	store, err := storage.New()
+	var opts config.Options
+	flag.StringVar(&opts.Root, "root", "", "journal directory (overrides $"+config.EnvHome+" and the config file)")
+	flag.StringVar(&opts.ConfigPath, "config", "", "path to the config file")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: teatime [flags] [command] [args]")
+		fmt.Fprintln(os.Stderr)
+		flag.PrintDefaults()
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Run 'teatime help' for the list of commands.")
+	}
+	flag.Parse()
+
+	cfg, err := config.Load(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := storage.NewAt(cfg.Root)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing teatime: %v\n", err)
 		os.Exit(1)
 	}
+	store.LockTimeout = cfg.LockTimeout
+	if cfg.Git.AutoCommit {
+		if err := store.EnableGitAutoCommit(cfg.Git.BatchWindow); err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing teatime: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	model := tui.NewModel(store)
+	if days := cfg.Trash.PurgeAfterDays; days > 0 {
+		if _, err := store.PurgeTrash(time.Duration(days) * 24 * time.Hour); err != nil {
+			fmt.Fprintf(os.Stderr, "teatime: could not empty old trash: %v\n", err)
+		}
+	}
+
+	// Any subcommand runs headless; no arguments opens the TUI.
+	if flag.NArg() > 0 {
+		app := cli.New(store, cfg)
+		err := app.Run(flag.Args())
+		closeStore(store)
+		if err != nil {
+			if !errors.Is(err, cli.ErrUsage) {
+				fmt.Fprintf(os.Stderr, "teatime: %v\n", err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	model := tui.NewModel(store, cfg)
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	closeStore(store)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running teatime: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// closeStore flushes pending background work such as batched git commits.
+func closeStore(store *storage.Store) {
+	if err := store.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "teatime: %v\n", err)
+	}
+}